@@ -0,0 +1,72 @@
+package fail_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/stack.v1"
+)
+
+func TestStackCapturer(t *testing.T) {
+	Convey("SetStackCapturer()", t, func() {
+		fixedCall, fixedStackTrace := fixedStack()
+		defer fail.SetStackCapturer(nil)
+		fail.SetStackCapturer(func(int) (stack.Call, stack.CallStack) {
+			return fixedCall, fixedStackTrace
+		})
+
+		Convey("should make frames of created errors deterministic", func() {
+			err1 := fail.New(errors.New("test"))
+			err2 := fail.NewWithInner(errors.New("test"), nil)
+			So(fail.GetLocation(err1), ShouldEqual, fail.GetLocation(err2))
+			So(fail.GetLocation(err1), ShouldContainSubstring, "(fixedStack)")
+			So(fail.GetFullDetails(err1), ShouldEqual, fail.GetFullDetails(err2))
+			So(fail.GetFullDetails(err1), ShouldStartWith, "*errors.errorString: test\n"+
+				fail.DetailsIndent+fail.FramesToString(fail.StackTraceToFrames(fixedStackTrace)[:1]))
+		})
+
+		Convey("should render frames lacking debug information as unknown", func() {
+			fail.SetStackCapturer(func(int) (stack.Call, stack.CallStack) {
+				return stack.Call{}, stack.CallStack{stack.Call{}, fixedCall}
+			})
+			err := fail.New(errors.New("test"))
+			So(fail.GetLocation(err), ShouldEqual, fail.UnknownFrameInfo)
+			So(fail.GetShortLocation(err), ShouldEqual, fail.UnknownFrameInfo)
+			So(fail.GetStackFrames(err)[0], ShouldResemble, fail.Frame{File: fail.UnknownFrameInfo, Function: fail.UnknownFrameInfo})
+			So(fail.GetStackTrace(err), ShouldStartWith, fail.UnknownFrameInfo+"\n")
+			So(fail.GetStackTrace(err), ShouldContainSubstring, "(fixedStack)")
+			So(fail.Frame{File: fail.UnknownFrameInfo, Line: 0, Function: "main.run"}.String(), ShouldEqual, "<unknown>:0 (main.run)")
+		})
+
+		Convey("should make frames of errors created by other constructors deterministic", func() {
+			errs := []error{
+				fail.NewLazy(errors.New("test")),
+				fail.NewLocationOnly(errors.New("test")),
+				fail.NewTrimmedAbove(errors.New("test"), "testing.tRunner"),
+				recoverPanic(),
+			}
+			for _, err := range errs {
+				So(fail.GetLocation(err), ShouldContainSubstring, "(fixedStack)")
+			}
+			So(fail.GetStackTrace(errs[0]), ShouldEqual, fail.GetStackTrace(fail.New(errors.New("test"))))
+			So(fail.GetStackTrace(errs[3]), ShouldEqual, fail.GetStackTrace(fail.New(errors.New("test"))))
+		})
+
+		Convey("should be restored by nil", func() {
+			fail.SetStackCapturer(nil)
+			So(fail.GetLocation(fail.New(errors.New("test"))), ShouldNotContainSubstring, "(fixedStack)")
+		})
+	})
+}
+
+func fixedStack() (stack.Call, stack.CallStack) {
+	stackTrace := stack.Trace().TrimRuntime()
+	return stackTrace[0], stackTrace
+}
+
+func recoverPanic() (err error) {
+	defer fail.Recover(&err)
+	panic("test")
+}
@@ -0,0 +1,426 @@
+package fail
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Is reports whether any error in the chain of the first argument matches the target.
+// The chain consists of the error itself and, recursively, its original errors (see ErrorWrapper)
+// and inner errors (see CompositeError), as well as errors returned by Unwrap.
+// An error matches the target if it is equal to it or if it has method Is(error) bool such that Is(target) returns true.
+func Is(err, target error) bool {
+	if err == nil || target == nil {
+		return err == target
+	}
+
+	isTargetComparable := reflect.TypeOf(target).Comparable()
+	found := false
+	walk(err, func(currErr error) bool {
+		if isTargetComparable && currErr == target {
+			found = true
+		} else if errWithIs, hasIs := currErr.(interface{ Is(error) bool }); hasIs && errWithIs.Is(target) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// IsAny reports whether the chain of the given error matches any of the targets (see Is).
+func IsAny(err error, targets ...error) bool {
+	for _, target := range targets {
+		if Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAll reports whether the chain of the given error matches all the targets (see Is),
+// e.g. to check errors aggregated by Join. It returns true if there are no targets.
+func IsAll(err error, targets ...error) bool {
+	for _, target := range targets {
+		if !Is(err, target) {
+			return false
+		}
+	}
+	return true
+}
+
+// As finds the first error in the chain of the given error that matches target, and if so,
+// sets target to that error value and returns true. Otherwise, it returns false.
+// The chain is walked the same way as in Is.
+// An error matches target if its concrete value is assignable to the value pointed to by target,
+// or if it has method As(interface{}) bool such that As(target) returns true.
+// As panics if target is not a non-nil pointer to either a type that implements error, or to any interface type.
+func As(err error, target interface{}) bool {
+	if target == nil {
+		panic("fail: target cannot be nil")
+	}
+	targetValue := reflect.ValueOf(target)
+	targetType := targetValue.Type()
+	if targetType.Kind() != reflect.Ptr || targetValue.IsNil() {
+		panic("fail: target must be a non-nil pointer")
+	}
+	targetElemType := targetType.Elem()
+	if targetElemType.Kind() != reflect.Interface && !targetElemType.Implements(errorType) {
+		panic("fail: *target must be interface or implement error")
+	}
+
+	found := false
+	walk(err, func(currErr error) bool {
+		if reflect.TypeOf(currErr).AssignableTo(targetElemType) {
+			targetValue.Elem().Set(reflect.ValueOf(currErr))
+			found = true
+		} else if errWithAs, hasAs := currErr.(interface{ As(interface{}) bool }); hasAs && errWithAs.As(target) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// Walk calls visit for the given error and then, depth-first, for all errors in its chain
+// (the chain is walked the same way as in Is: wrapped original error first, then inner one) until visit returns false.
+// Every error is visited once, so cyclic chains are walked without hanging.
+// Helpers like Find, GetTags or GetSeverity are built on the same walking.
+func Walk(err error, visit func(error) bool) {
+	walk(err, visit)
+}
+
+// Find returns the first error in the chain of the given error for which pred returns true, or nil if there is none.
+// The chain is walked the same way as in Is.
+func Find(err error, pred func(error) bool) error {
+	var result error
+	walk(err, func(currErr error) bool {
+		if pred(currErr) {
+			result = currErr
+		}
+		return result == nil
+	})
+	return result
+}
+
+// HasMessage reports whether any error in the chain of the given error has exactly the given message.
+// The chain is walked the same way as in Is.
+// It is intended for test assertions where the identity of the expected error is lost (e.g. it came over the network).
+func HasMessage(err error, message string) bool {
+	return hasMessageMatching(err, func(errMessage string) bool { return errMessage == message })
+}
+
+// HasMessageContaining works like HasMessage but reports whether any message in the chain contains the given substring.
+func HasMessageContaining(err error, substr string) bool {
+	return hasMessageMatching(err, func(errMessage string) bool { return strings.Contains(errMessage, substr) })
+}
+
+func hasMessageMatching(err error, match func(string) bool) bool {
+	found := false
+	walk(err, func(currErr error) bool {
+		found = match(currErr.Error())
+		return !found
+	})
+	return found
+}
+
+// GetMessage returns own message of the given error without messages of its inner errors:
+// Message for ErrWithReason, the message of the original error (with notes added by Annotate) for errors
+// created by this package regardless of IncludeInnerInError (stripped of ": <inner error message>" suffix
+// if the inner error is a part of it, like for Errorf), and for other errors their message
+// stripped of ": <inner error message>" suffix if the inner error (see GetInner and Unwrap) contributed it.
+// If the given error is nil then empty string is returned.
+func GetMessage(err error) string {
+	switch typedErr := err.(type) {
+	case nil:
+		return ""
+	case ErrWithReason:
+		return typedErr.Message
+	case *ErrWithReason:
+		return typedErr.Message
+	case *extendedError:
+		message := GetMessage(typedErr.originalError)
+		if typedErr.innerError != nil && typedErr.isInnerInMessage() {
+			message = strings.TrimSuffix(message, ": "+typedErr.innerError.Error())
+		}
+		if note := typedErr.metadata().note; note != "" {
+			message += " (" + note + ")"
+		}
+		return message
+	}
+
+	message := err.Error()
+	inner := GetInner(err)
+	if inner == nil {
+		inner = errors.Unwrap(err)
+	}
+	if inner != nil {
+		message = strings.TrimSuffix(message, ": "+inner.Error())
+	}
+	return message
+}
+
+// OfType returns the first error in the chain of the given error (the chain is walked the same way as in Is)
+// which is of type T (or implements T if it is an interface) and true, or zero value and false if there is none.
+// It is type-safe alternative to GetErrorByType and As.
+func OfType[T error](err error) (T, bool) {
+	var result T
+	found := false
+	walk(err, func(currErr error) bool {
+		result, found = currErr.(T)
+		return !found
+	})
+	return result, found
+}
+
+// GetErrorByReflectType returns error of the given type like GetErrorByType does, but accepts the type directly
+// instead of an example error: the chain of inner errors is walked comparing the type of each level (see GetType)
+// with the given type. Pointer and value of the same type are considered to be of the same type.
+// Like GetErrorByType it returns the original error of the found level (see GetOriginalError).
+// If there is no such error then nil is returned.
+func GetErrorByReflectType(whereToFind error, errType reflect.Type) error {
+	if errType == nil {
+		return nil
+	}
+	if errType.Kind() == reflect.Ptr {
+		errType = errType.Elem()
+	}
+
+	visited := visitedErrors{}
+	for whereToFind != nil {
+		currType := GetType(whereToFind)
+		if currType.Kind() == reflect.Ptr {
+			currType = currType.Elem()
+		}
+		if currType == errType {
+			return GetOriginalError(whereToFind)
+		}
+
+		compositeError, isCompositeError := whereToFind.(CompositeError)
+		if !isCompositeError || !visited.visit(whereToFind) {
+			return nil
+		}
+		whereToFind = compositeError.InnerError()
+	}
+	return nil
+}
+
+// ChainEqual checks if chains of inner errors (see GetInner) of the given errors are structurally equal:
+// they have the same length and errors on each level have the same message and type (see GetType).
+// Locations and stack traces are ignored, so it is useful to compare errors in tests.
+// Cyclic chains are compared up to the first repeated error.
+func ChainEqual(a, b error) bool {
+	visitedA, visitedB := visitedErrors{}, visitedErrors{}
+	for a != nil && b != nil {
+		if a.Error() != b.Error() || GetType(a) != GetType(b) {
+			return false
+		}
+		isNewA, isNewB := visitedA.visit(a), visitedB.visit(b)
+		if !isNewA || !isNewB {
+			return isNewA == isNewB
+		}
+		a, b = GetInner(a), GetInner(b)
+	}
+	return a == nil && b == nil
+}
+
+// Diff returns human-readable description of where chains of inner errors (see GetInner) of the given errors differ
+// comparing them like ChainEqual does: type and message mismatches on each level (numbered from 1)
+// and depth mismatch, each on its own line. If the chains are equal then empty string is returned.
+// It is intended for failure messages of tests.
+func Diff(got, want error) string {
+	var diffs []string
+	visitedGot, visitedWant := visitedErrors{}, visitedErrors{}
+	level := 1
+	for ; got != nil && want != nil; level++ {
+		if gotType, wantType := GetType(got), GetType(want); gotType != wantType {
+			diffs = append(diffs, fmt.Sprintf("level %d: type mismatch: got %v, want %v", level, gotType, wantType))
+		}
+		if got.Error() != want.Error() {
+			diffs = append(diffs, fmt.Sprintf("level %d: message mismatch: got %q, want %q", level, got.Error(), want.Error()))
+		}
+		isNewGot, isNewWant := visitedGot.visit(got), visitedWant.visit(want)
+		if !isNewGot || !isNewWant {
+			return strings.Join(diffs, "\n")
+		}
+		got, want = GetInner(got), GetInner(want)
+	}
+	if got != nil || want != nil {
+		diffs = append(diffs, fmt.Sprintf("depth mismatch: got %d levels, want %d levels", level-1+Depth(got), level-1+Depth(want)))
+	}
+	return strings.Join(diffs, "\n")
+}
+
+// Depth returns the number of levels in the chain of inner errors (see GetInner) of the given error:
+// 1 if the given error has no inner error, 0 if the given error is nil.
+// If the chain is cyclic then only distinct errors are counted.
+func Depth(err error) int {
+	result := 0
+	visited := visitedErrors{}
+	for currErr := err; currErr != nil && visited.visit(currErr); currErr = GetInner(currErr) {
+		result++
+	}
+	return result
+}
+
+// WrapCount returns how many times the error created by this package was re-wrapped by this package
+// (by New, Wrap and other constructors) to get the given error: the number of errors created by this package
+// in the chain of wrapped errors of the given error minus one. Errors wrapped by Errorf and Mask are followed,
+// while inner errors given to NewWithInner are not, as they are reasons of new errors rather than wrapped ones.
+// Errors which only decorate the same error (like WithField and WithTag do) are not counted.
+// High count relative to the number of distinct messages indicates over-wrapping.
+// If there is no error created by this package then 0 is returned.
+func WrapCount(err error) int {
+	count := 0
+	visited := visitedErrors{}
+	for currErr := err; currErr != nil && visited.visit(currErr); {
+		if extErr, isExtErr := currErr.(*extendedError); isExtErr {
+			count++
+			currErr = extErr.originalError
+			if extErr.innerInMsg || extErr.masked {
+				// inner error of Errorf and Mask is the wrapped error
+				currErr = extErr.innerError
+			}
+			continue
+		}
+		currErr = errors.Unwrap(currErr)
+	}
+	if count == 0 {
+		return 0
+	}
+	return count - 1
+}
+
+// Types returns distinct types of errors on all levels of the chain of inner errors (see GetInner) of the given error
+// in the order they are found. Types of the original errors are collected (see GetType).
+// It helps to decide how to handle composite error. If the given error is nil then nil is returned.
+func Types(err error) []reflect.Type {
+	var result []reflect.Type
+	seen := map[reflect.Type]bool{}
+	visited := visitedErrors{}
+	for currErr := err; currErr != nil && visited.visit(currErr); currErr = GetInner(currErr) {
+		if errType := GetType(currErr); !seen[errType] {
+			seen[errType] = true
+			result = append(result, errType)
+		}
+	}
+	return result
+}
+
+// FlattenSeparator separates levels of the chain of inner errors in the output of Flatten.
+var FlattenSeparator = " -> "
+
+// Flatten returns single-line summary of the given error and all its inner errors (see GetInner):
+// "type: message" of each level (like in GetFullDetails, without fields and stack traces) separated by FlattenSeparator.
+// If the chain of inner errors is cyclic then the summary ends with "<cycle detected>".
+func Flatten(err error) string {
+	var levels []string
+	visited := visitedErrors{}
+	for currErr := err; currErr != nil; currErr = GetInner(currErr) {
+		if !visited.visit(currErr) {
+			levels = append(levels, cycleDetectedMarker)
+			break
+		}
+		levels = append(levels, fmt.Sprintf("%v: %v", getTypeName(currErr), currErr))
+	}
+	return strings.Join(levels, FlattenSeparator)
+}
+
+// StripForTest returns deterministic representation of the given error and all errors in its chain
+// (the chain is walked the same way as in Is) intended for golden-file comparison in tests.
+// Each error is rendered on its own line as "type: message". Errors created by this package are skipped
+// as they only decorate errors they wrap with locations, stack traces and other metadata which are not stable.
+// If the given error is nil then empty string is returned.
+func StripForTest(err error) string {
+	var lines []string
+	walk(err, func(currErr error) bool {
+		if _, isExtErr := currErr.(*extendedError); !isExtErr {
+			lines = append(lines, fmt.Sprintf("%T: %v", currErr, currErr))
+		}
+		return true
+	})
+	return strings.Join(lines, "\n")
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// walk calls visit for the given error and then, depth-first, for all errors it wraps
+// (original error first, then inner one) until visit returns false.
+// Every error is visited once, so cyclic chains are walked without hanging.
+// It returns false if walking was stopped by visit.
+func walk(err error, visit func(error) bool) bool {
+	return walkOnce(err, visit, visitedErrors{}, false)
+}
+
+// walkMetadata walks like walk does, but does not descend into errors wrapped by errors sealed by SealMetadata.
+func walkMetadata(err error, visit func(error) bool) bool {
+	return walkOnce(err, visit, visitedErrors{}, true)
+}
+
+func walkOnce(err error, visit func(error) bool, visited visitedErrors, stopAtSealed bool) bool {
+	if err == nil || !visited.visit(err) {
+		return true
+	}
+	if !visit(err) {
+		return false
+	}
+	if extErr, isExtErr := err.(*extendedError); isExtErr && extErr.sealed && stopAtSealed {
+		return true
+	}
+	for _, wrappedErr := range unwrap(err) {
+		if !walkOnce(wrappedErr, visit, visited, stopAtSealed) {
+			return false
+		}
+	}
+	return true
+}
+
+// visitedErrors is a set of visited errors used to detect cycles in error chains.
+// Errors are identified by pointer, so only errors of pointer types are tracked.
+type visitedErrors map[visitedError]bool
+
+type visitedError struct {
+	errType reflect.Type
+	pointer uintptr
+}
+
+// visit marks the error as visited and returns false if it has been already visited.
+func (visited visitedErrors) visit(err error) bool {
+	errValue := reflect.ValueOf(err)
+	if errValue.Kind() != reflect.Ptr {
+		return true
+	}
+
+	key := visitedError{errValue.Type(), errValue.Pointer()}
+	if visited[key] {
+		return false
+	}
+	visited[key] = true
+	return true
+}
+
+// unwrap returns errors directly wrapped by the given error.
+// Unwrap methods are preferred, otherwise ErrorWrapper and CompositeError are used.
+func unwrap(err error) []error {
+	switch unwrapper := err.(type) {
+	case interface{ Unwrap() []error }:
+		return unwrapper.Unwrap()
+	case interface{ Unwrap() error }:
+		return []error{unwrapper.Unwrap()}
+	}
+
+	var result []error
+	if errorWrapper, isErrorWrapper := err.(ErrorWrapper); isErrorWrapper {
+		result = append(result, errorWrapper.OriginalError())
+	}
+	if compositeError, isCompositeError := err.(CompositeError); isCompositeError {
+		result = append(result, compositeError.InnerError())
+	}
+	if result == nil {
+		if cause := unwrapByRegistered(err); cause != nil {
+			result = append(result, cause)
+		}
+	}
+	return result
+}
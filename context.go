@@ -0,0 +1,117 @@
+package fail
+
+import (
+	"context"
+	"sync"
+)
+
+// ContextErrFieldKey is the key of field with error of the context which is already done (see NewCtx).
+const ContextErrFieldKey = "ctx.err"
+
+var (
+	contextKeysMutex sync.RWMutex
+	contextKeys      = map[string]interface{}{}
+)
+
+// IsContextCanceled reports whether there is context.Canceled in the chain of the given error (see Is).
+func IsContextCanceled(err error) bool {
+	return Is(err, context.Canceled)
+}
+
+// IsDeadlineExceeded reports whether there is context.DeadlineExceeded in the chain of the given error (see Is).
+func IsDeadlineExceeded(err error) bool {
+	return Is(err, context.DeadlineExceeded)
+}
+
+// RegisterContextKey registers context key, so NewCtx attaches value of the context for such key
+// as a field with the given name (e.g. request id stored in context by a middleware).
+// It is safe for concurrent use.
+func RegisterContextKey(fieldKey string, contextKey interface{}) {
+	contextKeysMutex.Lock()
+	defer contextKeysMutex.Unlock()
+
+	contextKeys[fieldKey] = contextKey
+}
+
+// NewCtx creates a new error like New does and attaches information about the given context to it as fields:
+// error of the context if it is already done (under ContextErrFieldKey, e.g. "context canceled")
+// and non-nil values of the context for keys registered by RegisterContextKey.
+// It helps to diagnose whether an operation failed because its context was done.
+// Newly created error implements ErrorWithFields.
+func NewCtx(ctx context.Context, err error, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	extErr := newExtendedError(err, nil, stackSkip)
+	extErr.fields = contextFields(ctx)
+	return enrich(extErr)
+}
+
+// pushedFieldsKey is the key of context value with fields pushed by PushContext.
+type pushedFieldsKey struct{}
+
+// PushContext returns a copy of the given context carrying the given fields in addition to the fields
+// pushed to the given context earlier, so errors created by NewFromContext downstream pick them up
+// (e.g. user id and request id set once by a request handler).
+// As Go lacks goroutine-local storage, fields are scoped by the returned context:
+// they are popped simply by using the parent context again.
+func PushContext(ctx context.Context, fields map[string]interface{}) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, pushedFieldsKey{}, mergeFields(pushedFields(ctx), fields))
+}
+
+// NewFromContext creates a new error like New does and attaches fields pushed to the given context by PushContext
+// (fields pushed later win on conflict).
+// Newly created error implements ErrorWithFields.
+func NewFromContext(ctx context.Context, err error, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	extErr := newExtendedError(err, nil, stackSkip)
+	extErr.fields = mergeFields(pushedFields(ctx))
+	return enrich(extErr)
+}
+
+// pushedFields returns fields pushed to the given context by PushContext or nil if there are none.
+// The result must not be modified.
+func pushedFields(ctx context.Context) map[string]interface{} {
+	fields, _ := ctx.Value(pushedFieldsKey{}).(map[string]interface{})
+	return fields
+}
+
+// contextFields returns fields describing the given context or nil if there is nothing to describe.
+func contextFields(ctx context.Context) map[string]interface{} {
+	contextKeysMutex.RLock()
+	defer contextKeysMutex.RUnlock()
+
+	var result map[string]interface{}
+	addField := func(key string, value interface{}) {
+		if result == nil {
+			result = make(map[string]interface{})
+		}
+		result[key] = value
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		addField(ContextErrFieldKey, ctxErr.Error())
+	}
+	for fieldKey, contextKey := range contextKeys {
+		if value := ctx.Value(contextKey); value != nil {
+			addField(fieldKey, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package fail_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestContext(t *testing.T) {
+	Convey("IsContextCanceled() and IsDeadlineExceeded()", t, func() {
+		Convey("should detect deadline exceeded wrapped three levels deep", func() {
+			err := fail.Wrap(fail.NewWithInner(fail.News("request failed"), fail.New(context.DeadlineExceeded)), "handling")
+			So(fail.IsDeadlineExceeded(err), ShouldBeTrue)
+			So(fail.IsContextCanceled(err), ShouldBeFalse)
+		})
+
+		Convey("should detect cancellation of real context", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			err := fail.Wrap(fail.New(ctx.Err()), "handling")
+			So(fail.IsContextCanceled(err), ShouldBeTrue)
+			So(fail.IsDeadlineExceeded(err), ShouldBeFalse)
+		})
+
+		Convey("should not detect anything in other errors", func() {
+			So(fail.IsContextCanceled(errors.New("canceled")), ShouldBeFalse)
+			So(fail.IsDeadlineExceeded(nil), ShouldBeFalse)
+		})
+	})
+
+	Convey("NewCtx()", t, func() {
+		type requestIDKey struct{}
+		fail.RegisterContextKey("request.id", requestIDKey{})
+		ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+
+		Convey("should attach error of canceled context", func() {
+			canceledCtx, cancel := context.WithCancel(ctx)
+			cancel()
+			err := fail.NewCtx(canceledCtx, errors.New("query failed"))
+			So(fail.GetFields(err), ShouldResemble, map[string]interface{}{
+				fail.ContextErrFieldKey: "context canceled",
+				"request.id":            "req-1",
+			})
+			So(fail.GetLocation(err), ShouldContainSubstring, "context_test.go")
+		})
+
+		Convey("should attach registered values only for active context", func() {
+			err := fail.NewCtx(ctx, errors.New("query failed"))
+			So(fail.GetFields(err), ShouldResemble, map[string]interface{}{"request.id": "req-1"})
+			So(fail.GetFields(fail.NewCtx(context.Background(), errors.New("query failed"))), ShouldBeNil)
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(fail.NewCtx(ctx, nil), ShouldBeNil)
+		})
+	})
+	Convey("PushContext() and NewFromContext()", t, func() {
+		ctx := fail.PushContext(context.Background(), map[string]interface{}{"userID": 42, "requestID": "req-1"})
+
+		Convey("should auto-attach pushed fields", func() {
+			err := fail.NewFromContext(ctx, errors.New("query failed"))
+			So(fail.GetFields(err), ShouldResemble, map[string]interface{}{"userID": 42, "requestID": "req-1"})
+			So(fail.GetLocation(err), ShouldContainSubstring, "context_test.go")
+		})
+
+		Convey("should stack fields with later ones winning", func() {
+			innerCtx := fail.PushContext(ctx, map[string]interface{}{"requestID": "req-2", "step": "load"})
+			So(fail.GetFields(fail.NewFromContext(innerCtx, errors.New("query failed"))), ShouldResemble,
+				map[string]interface{}{"userID": 42, "requestID": "req-2", "step": "load"})
+
+			Convey("which are popped by using the parent context", func() {
+				So(fail.GetFields(fail.NewFromContext(ctx, errors.New("query failed"))), ShouldResemble,
+					map[string]interface{}{"userID": 42, "requestID": "req-1"})
+			})
+		})
+
+		Convey("should attach nothing if no fields are pushed", func() {
+			So(fail.GetFields(fail.NewFromContext(context.Background(), errors.New("query failed"))), ShouldBeNil)
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(fail.NewFromContext(ctx, nil), ShouldBeNil)
+		})
+	})
+}
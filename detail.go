@@ -0,0 +1,51 @@
+package fail
+
+// Detailed is error which carries an arbitrary detail object (like a validation report) alongside itself.
+//
+// Detail returns error's detail or nil if there is no detail.
+type Detailed interface {
+	error
+	Detail() interface{}
+}
+
+func (extErr extendedError) Detail() interface{} {
+	if detail := extErr.metadata().detail; detail != nil {
+		return detail
+	}
+	if detailed, isDetailed := extErr.originalError.(Detailed); isDetailed {
+		return detailed.Detail()
+	}
+	return nil
+}
+
+// WithDetail returns an error with the given detail object attached to the given error.
+// If the given error is created by this package then the result keeps its location and stack trace,
+// otherwise the given error is wrapped capturing location and stack trace of the WithDetail caller.
+// Newly created error implements Detailed.
+func WithDetail(err error, detail interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	if extErr, isExtErr := err.(*extendedError); isExtErr {
+		extErrCopy := *extErr
+		extErrCopy.writableMetadata().detail = detail
+		return &extErrCopy
+	}
+	extErr := newExtendedError(err, nil, 1)
+	extErr.writableMetadata().detail = detail
+	return enrich(extErr)
+}
+
+// GetDetail returns the detail of the nearest error having one found in the chain of the given error
+// (the chain is walked the same way as in Is). If there is no such error then false is returned.
+func GetDetail(err error) (interface{}, bool) {
+	var result interface{}
+	walk(err, func(currErr error) bool {
+		if detailed, isDetailed := currErr.(Detailed); isDetailed {
+			result = detailed.Detail()
+		}
+		return result == nil
+	})
+	return result, result != nil
+}
@@ -0,0 +1,69 @@
+package fail_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type validationReport struct {
+	Field  string
+	Reason string
+}
+
+func TestDetail(t *testing.T) {
+	Convey("WithDetail()", t, func() {
+		report := validationReport{"email", "invalid format"}
+
+		Convey("should attach detail", func() {
+			err := fail.WithDetail(errors.New("invalid input"), report)
+			So(err.(fail.Detailed).Detail(), ShouldResemble, report)
+			So(fail.GetLocation(err), ShouldContainSubstring, "detail_test.go")
+		})
+
+		Convey("should keep location of error created by this package", func() {
+			extErr := fail.News("invalid input")
+			err := fail.WithDetail(extErr, &report)
+			So(fail.GetLocation(err), ShouldEqual, fail.GetLocation(extErr))
+			_, hasDetail := fail.GetDetail(extErr)
+			So(hasDetail, ShouldBeFalse)
+		})
+
+		Convey("should keep other metadata without modifying the given error", func() {
+			tagged := fail.WithTag(fail.News("invalid input"), "validation")
+			err := fail.WithDetail(fail.WithDetail(tagged, "first"), "second")
+			So(fail.GetTags(err), ShouldResemble, []string{"validation"})
+			detail, _ := fail.GetDetail(err)
+			So(detail, ShouldEqual, "second")
+			_, hasDetail := fail.GetDetail(tagged)
+			So(hasDetail, ShouldBeFalse)
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(fail.WithDetail(nil, report), ShouldBeNil)
+		})
+	})
+
+	Convey("GetDetail()", t, func() {
+		Convey("should return the nearest detail in the chain", func() {
+			inner := fail.WithDetail(errors.New("invalid input"), &validationReport{"email", "invalid format"})
+			err := fail.Wrap(inner, "creating user")
+			detail, hasDetail := fail.GetDetail(err)
+			So(hasDetail, ShouldBeTrue)
+			So(detail.(*validationReport).Field, ShouldEqual, "email")
+
+			outer := fail.WithDetail(err, "outer detail")
+			detail, _ = fail.GetDetail(outer)
+			So(detail, ShouldEqual, "outer detail")
+		})
+
+		Convey("should return false if there is no detail", func() {
+			_, hasDetail := fail.GetDetail(fail.Wrap(errors.New("failed"), "test"))
+			So(hasDetail, ShouldBeFalse)
+			_, hasDetail = fail.GetDetail(nil)
+			So(hasDetail, ShouldBeFalse)
+		})
+	})
+}
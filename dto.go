@@ -0,0 +1,161 @@
+package fail
+
+import "strings"
+
+// ErrorDTO is serializable representation of an error and its inner errors, e.g. to transport it over RPC.
+type ErrorDTO struct {
+	Message  string                 `json:"message"`
+	Type     string                 `json:"type,omitempty"`
+	Location string                 `json:"location,omitempty"`
+	Stack    []string               `json:"stack,omitempty"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+	Code     string                 `json:"code,omitempty"`
+	Inner    *ErrorDTO              `json:"inner,omitempty"`
+	Errors   []*ErrorDTO            `json:"errors,omitempty"`
+}
+
+// Encode converts the given error and its inner errors (see GetInner) to ErrorDTO.
+// Type is the name of the error (see ErrorWithName) or the name of its type (see GetType),
+// stack contains formatted frames (see StackFormatter). Fields are not redacted.
+// Errors aggregated by the error or its original error (like MultiError does) are encoded as its errors.
+// If the chain of inner errors is cyclic then encoding stops at the first repeated error.
+// If the given error is nil then nil is returned.
+func Encode(err error) *ErrorDTO {
+	var result *ErrorDTO
+	dto := &result
+	visited := visitedErrors{}
+	for currErr := err; currErr != nil && visited.visit(currErr); currErr = GetInner(currErr) {
+		*dto = encodeOne(currErr)
+		dto = &(*dto).Inner
+	}
+	return result
+}
+
+func encodeOne(err error) *ErrorDTO {
+	result := &ErrorDTO{
+		Message: err.Error(),
+		Type:    getTypeName(err),
+	}
+	if errorWithLocation, isErrorWithLocation := err.(ErrorWithLocation); isErrorWithLocation {
+		result.Location = errorWithLocation.Location()
+	}
+	if errorWithStackTrace, isErrorWithStackTrace := err.(ErrorWithStackTrace); isErrorWithStackTrace {
+		if stackTrace := errorWithStackTrace.StackTrace(); stackTrace != "" {
+			result.Stack = strings.Split(stackTrace, "\n")
+		}
+	}
+	if errorWithFields, isErrorWithFields := err.(ErrorWithFields); isErrorWithFields {
+		result.Fields = errorWithFields.Fields()
+	}
+	if errorWithCode, isErrorWithCode := err.(ErrorWithCode); isErrorWithCode {
+		result.Code = errorWithCode.Code()
+	}
+	for _, memberErr := range getAggregatedErrors(err) {
+		result.Errors = append(result.Errors, Encode(memberErr))
+	}
+	return result
+}
+
+// Decode reconstructs error from the given ErrorDTO.
+// Reconstructed error is *TransportError which implements CompositeError, ErrorWithLocation, ErrorWithStackTrace, ErrorWithFields,
+// ErrorWithCode and ErrorWithName (which returns encoded type) and provides decoded aggregated errors by Errors,
+// so it can be handled like the encoded one
+// (except matching by identity or type).
+// If the given ErrorDTO is nil then nil is returned.
+func Decode(dto *ErrorDTO) error {
+	if dto == nil {
+		return nil
+	}
+	result := &TransportError{dto: *dto, inner: Decode(dto.Inner)}
+	for _, memberDTO := range dto.Errors {
+		result.errs = append(result.errs, Decode(memberDTO))
+	}
+	return result
+}
+
+// Snapshot returns self-contained copy of the given error and its inner errors (see GetInner) like Decode(Encode(err))
+// with fields copied deeply (nested maps and slices of types map[string]interface{} and []interface{} are copied as well),
+// so later changes of the given error do not affect the snapshot (e.g. to store errors for later reporting).
+// If the given error is nil then nil is returned.
+func Snapshot(err error) error {
+	dto := Encode(err)
+	deepCopyFields(dto)
+	return Decode(dto)
+}
+
+// deepCopyFields replaces fields of the given ErrorDTO, its inner and aggregated ones by their deep copies.
+func deepCopyFields(dto *ErrorDTO) {
+	for levelDTO := dto; levelDTO != nil; levelDTO = levelDTO.Inner {
+		if levelDTO.Fields != nil {
+			levelDTO.Fields = deepCopyValue(levelDTO.Fields).(map[string]interface{})
+		}
+		for _, memberDTO := range levelDTO.Errors {
+			deepCopyFields(memberDTO)
+		}
+	}
+}
+
+// deepCopyValue returns deep copy of maps and slices of types map[string]interface{} and []interface{},
+// other values are returned as is.
+func deepCopyValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typedValue))
+		for key, itemValue := range typedValue {
+			result[key] = deepCopyValue(itemValue)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typedValue))
+		for i, itemValue := range typedValue {
+			result[i] = deepCopyValue(itemValue)
+		}
+		return result
+	}
+	return value
+}
+
+// TransportError is error reconstructed from ErrorDTO (see Decode).
+// It can be sent by encoding/gob, e.g. as an error in replies of net/rpc (see ToTransportError).
+type TransportError struct {
+	dto   ErrorDTO
+	inner error
+	errs  []error
+}
+
+func (err *TransportError) Error() string {
+	return err.dto.Message
+}
+
+func (err *TransportError) ErrorName() string {
+	return err.dto.Type
+}
+
+func (err *TransportError) Location() string {
+	return err.dto.Location
+}
+
+func (err *TransportError) StackTrace() string {
+	return strings.Join(err.dto.Stack, "\n")
+}
+
+func (err *TransportError) Fields() map[string]interface{} {
+	return err.dto.Fields
+}
+
+func (err *TransportError) Code() string {
+	return err.dto.Code
+}
+
+func (err *TransportError) InnerError() error {
+	return err.inner
+}
+
+// Errors returns decoded errors aggregated by the encoded error (see MultiError) or nil if there are none.
+func (err *TransportError) Errors() []error {
+	return err.errs
+}
+
+func (err *TransportError) Unwrap() error {
+	return err.inner
+}
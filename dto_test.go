@@ -0,0 +1,96 @@
+package fail_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDTO(t *testing.T) {
+	Convey("Encode() and Decode()", t, func() {
+		inner := fail.NewWithCode(fail.WithField(errors.New("not found"), "id", 42), "NOT_FOUND")
+		err := fail.NewWithInner(fail.News("cannot load user"), inner)
+
+		Convey("should encode error chain", func() {
+			dto := fail.Encode(err)
+			So(dto.Message, ShouldEqual, "cannot load user")
+			So(dto.Type, ShouldEqual, "*errors.errorString")
+			So(dto.Location, ShouldEqual, fail.GetLocation(err))
+			So(dto.Stack, ShouldNotBeEmpty)
+			So(dto.Inner.Message, ShouldEqual, "not found")
+			So(dto.Inner.Code, ShouldEqual, "NOT_FOUND")
+			So(dto.Inner.Fields, ShouldResemble, map[string]interface{}{"id": 42})
+			So(dto.Inner.Inner, ShouldBeNil)
+		})
+
+		Convey("should preserve message, location, stack, fields and code on round trip", func() {
+			data, jsonErr := json.Marshal(fail.Encode(err))
+			So(jsonErr, ShouldBeNil)
+			var dto fail.ErrorDTO
+			So(json.Unmarshal(data, &dto), ShouldBeNil)
+			decoded := fail.Decode(&dto)
+
+			So(decoded.Error(), ShouldEqual, err.Error())
+			So(fail.GetLocation(decoded), ShouldEqual, fail.GetLocation(err))
+			So(fail.GetStackTrace(decoded), ShouldEqual, fail.GetStackTrace(err))
+			So(fail.GetFullDetails(decoded), ShouldEqual, fail.GetFullDetails(err))
+
+			decodedInner := fail.GetInner(decoded)
+			So(decodedInner.Error(), ShouldEqual, "not found")
+			So(fail.GetCode(decoded), ShouldEqual, "NOT_FOUND")
+			So(fail.GetFields(decoded), ShouldResemble, map[string]interface{}{"id": 42.0})
+			So(fail.GetStackTrace(decodedInner), ShouldEqual, fail.GetStackTrace(inner))
+			So(fail.GetInner(decodedInner), ShouldBeNil)
+		})
+
+		Convey("should return nil for nil", func() {
+			So(fail.Encode(nil), ShouldBeNil)
+			So(fail.Decode(nil), ShouldBeNil)
+		})
+	})
+
+	Convey("Snapshot()", t, func() {
+		fields := map[string]interface{}{"id": 42, "tags": []interface{}{"a"}, "user": map[string]interface{}{"name": "bob"}}
+		inner := fail.New(mutableFieldsError{fields})
+		err := fail.NewWithInner(fail.News("cannot load user"), inner)
+		snapshot := fail.Snapshot(err)
+
+		Convey("should copy message, location, stack trace and inner error", func() {
+			So(snapshot.Error(), ShouldEqual, err.Error())
+			So(fail.GetLocation(snapshot), ShouldEqual, fail.GetLocation(err))
+			So(fail.GetStackTrace(snapshot), ShouldEqual, fail.GetStackTrace(err))
+			So(fail.GetFullDetails(snapshot), ShouldEqual, fail.GetFullDetails(err))
+		})
+
+		Convey("should not be affected by changes of fields of the original error", func() {
+			fields["id"] = 43
+			fields["tags"].([]interface{})[0] = "b"
+			fields["user"].(map[string]interface{})["name"] = "alice"
+			So(fail.GetFields(snapshot), ShouldResemble, map[string]interface{}{
+				"id":   42,
+				"tags": []interface{}{"a"},
+				"user": map[string]interface{}{"name": "bob"},
+			})
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(fail.Snapshot(nil), ShouldBeNil)
+		})
+	})
+}
+
+// mutableFieldsError exposes its fields map directly.
+type mutableFieldsError struct {
+	fields map[string]interface{}
+}
+
+func (err mutableFieldsError) Error() string {
+	return "not found"
+}
+
+func (err mutableFieldsError) Fields() map[string]interface{} {
+	return err.fields
+}
@@ -0,0 +1,41 @@
+package fail
+
+import "sync"
+
+var (
+	enrichersMutex sync.RWMutex
+	enrichers      []func(error) error
+)
+
+// RegisterEnricher registers function which is applied to every error created by constructors of this package
+// (New, NewWithInner, Wrap, NewWithCode, etc.) before it is returned, e.g. to attach service name to all errors.
+// Enrichers are applied in registration order, each one gets the result of the previous one.
+// Enrichers should annotate the error by WithField and similar functions
+// rather than by constructors of this package, which would apply enrichers again.
+// It is safe for concurrent use.
+func RegisterEnricher(enricher func(error) error) {
+	enrichersMutex.Lock()
+	defer enrichersMutex.Unlock()
+
+	enrichers = append(enrichers, enricher)
+}
+
+// ResetEnrichers unregisters all enrichers registered by RegisterEnricher.
+// It is safe for concurrent use.
+func ResetEnrichers() {
+	enrichersMutex.Lock()
+	defer enrichersMutex.Unlock()
+
+	enrichers = nil
+}
+
+// enrich applies registered enrichers to the given newly created error.
+func enrich(err error) error {
+	enrichersMutex.RLock()
+	defer enrichersMutex.RUnlock()
+
+	for _, enricher := range enrichers {
+		err = enricher(err)
+	}
+	return err
+}
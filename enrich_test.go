@@ -0,0 +1,40 @@
+package fail_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEnrichers(t *testing.T) {
+	Convey("RegisterEnricher()", t, func() {
+		defer fail.ResetEnrichers()
+		fail.RegisterEnricher(func(err error) error { return fail.WithField(err, "service", "users") })
+		fail.RegisterEnricher(func(err error) error { return fail.WithField(err, "order", fail.GetFields(err)["service"]) })
+
+		Convey("should apply enrichers to newly created errors", func() {
+			for _, err := range []error{
+				fail.New(errors.New("test")),
+				fail.News("test"),
+				fail.Wrap(errors.New("test"), "wrapped"),
+				fail.NewWithCode(errors.New("test"), "TEST"),
+				fail.NewLazy(errors.New("test")),
+				recoverPanic(),
+			} {
+				So(fail.GetFields(err), ShouldResemble, map[string]interface{}{"service": "users", "order": "users"})
+			}
+		})
+
+		Convey("should keep location of the created error", func() {
+			err := fail.News("test")
+			So(fail.GetLocation(err), ShouldContainSubstring, "enrich_test.go")
+		})
+
+		Convey("should not apply enrichers after reset", func() {
+			fail.ResetEnrichers()
+			So(fail.GetFields(fail.News("test")), ShouldBeNil)
+		})
+	})
+}
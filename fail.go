@@ -5,9 +5,14 @@ import (
 	"bytes"
 	"fmt"
 	"gopkg.in/stack.v1"
+	"io"
 	"reflect"
+	"runtime"
+	"strconv"
 	"strings"
+	"time"
 	"errors"
+	"sort"
 )
 
 // CompositeError is the interface that represents an error that can provide information about its cause/inner error.
@@ -34,6 +39,44 @@ type ErrorWithStackTrace interface {
 	StackTrace() string
 }
 
+// ErrorWithStackFrames is the interface that represents an error that has information about stack trace as frames.
+//
+// StackFrames is supposed to return stack trace as a slice of frames where each frame has information about code line and function.
+type ErrorWithStackFrames interface {
+	error
+	StackFrames() []Frame
+}
+
+// Frame is a single frame of stack trace.
+// Package is the import path of the package of the function.
+type Frame struct {
+	File     string
+	Line     int
+	Function string
+	Package  string
+}
+
+// UnknownFrameInfo replaces file and function of frames lacking debug information (e.g. in stripped binaries).
+const UnknownFrameInfo = "<unknown>"
+
+// String returns frame in the form "file:line (function)".
+// If both file and function of the frame are unknown (see UnknownFrameInfo) then just UnknownFrameInfo is returned.
+func (frame Frame) String() string {
+	if frame.File == UnknownFrameInfo && frame.Function == UnknownFrameInfo {
+		return UnknownFrameInfo
+	}
+	return fmt.Sprintf("%v:%v (%v)", frame.File, frame.Line, frame.Function)
+}
+
+// StackFormatter renders a single frame of stack traces and locations produced by this package.
+// By default it is DefaultStackFormatter. Nil value means default formatting as well.
+var StackFormatter = DefaultStackFormatter
+
+// DefaultStackFormatter renders frame in the form "file:line (function)".
+func DefaultStackFormatter(frame Frame) string {
+	return frame.String()
+}
+
 // ErrorWrapper is the interface that represents an object that wraps original error.
 //
 // GetOriginalError returns original error that was wrapped.
@@ -49,26 +92,119 @@ type ErrorWithFields interface {
 	Fields() map[string]interface{}
 }
 
+// MaxStackDepth is the maximum number of stack trace frames retained by errors created by this package.
+// Zero or negative value means unlimited depth.
+var MaxStackDepth = 0
+
+// CaptureStackTraces enables capturing of stack traces by errors created by this package.
+// If it is disabled then only locations are captured (which is cheap) and stack traces of created errors are empty.
+var CaptureStackTraces = true
+
+// ErrorWithCode is error which provides machine-readable code.
+//
+// Code returns error's code or empty string if there is no code.
+type ErrorWithCode interface {
+	error
+	Code() string
+}
+
 // ErrWithReason is error with message and reason.
 // Implements CompositeError
+//
+// ErrWithReason intentionally does not implement ErrorWrapper: it is a new error caused by its reason
+// rather than the reason decorated with extra information. So GetType and GetOriginalError
+// return ErrWithReason itself (or its type) while the reason is available via GetInner, Cause, Is and As.
 type ErrWithReason struct {
 	Message string
 	Reason  error
 }
 
+// Error returns "<message>: <reason>" or just the message if there is no reason.
 func (err ErrWithReason) Error() string {
+	if err.Reason == nil {
+		return err.Message
+	}
 	return fmt.Sprintf("%v: %v", err.Message, err.Reason)
 }
 // InnerError implements Composite.InnerError
 func (err ErrWithReason) InnerError() error {
 	return err.Reason
 }
+// Unwrap returns the reason, so errors.Is and errors.As can walk the chain.
+func (err ErrWithReason) Unwrap() error {
+	return err.Reason
+}
+// Is reports whether target is ErrWithReason (or a pointer to it) with the same message, reason is ignored.
+// It allows to match errors by message as a category, e.g. errors.Is(err, ErrWithReason{Message: "loading user"}).
+func (err ErrWithReason) Is(target error) bool {
+	switch targetErr := target.(type) {
+	case ErrWithReason:
+		return targetErr.Message == err.Message
+	case *ErrWithReason:
+		return targetErr != nil && targetErr.Message == err.Message
+	}
+	return false
+}
 
 type extendedError struct {
 	originalError error
 	innerError    error
 	location      stack.Call
 	stackTrace    stack.CallStack
+	lazyStack     *lazyStackTrace
+	fields        map[string]interface{}
+	code          string
+	meta          *errorMetadata
+	sealed        bool
+	innerInMsg    bool
+	masked        bool
+}
+
+// errorMetadata is rarely used metadata of extended error. It is allocated only for errors having any,
+// so errors created by New stay small. It is shared by copies of extended error and must not be modified
+// once the error is created (see writableMetadata).
+type errorMetadata struct {
+	severity     Severity
+	kind         Kind
+	httpStatus   int
+	retryability retryability
+	retryAfter   time.Duration
+	temporary    bool
+	goroutineID  int
+	time         time.Time
+	name         string
+	tags         []string
+	detail       interface{}
+	note         string
+}
+
+// metadata returns metadata of the error or zero metadata if there is none.
+func (extErr extendedError) metadata() errorMetadata {
+	if extErr.meta == nil {
+		return errorMetadata{}
+	}
+	return *extErr.meta
+}
+
+// writableMetadata replaces metadata of the error by its copy (shared metadata of the error it may be copied from
+// are left intact) and returns it for modification.
+func (extErr *extendedError) writableMetadata() *errorMetadata {
+	meta := &errorMetadata{}
+	if extErr.meta != nil {
+		*meta = *extErr.meta
+	}
+	extErr.meta = meta
+	return meta
+}
+
+// captureMetadata returns metadata captured at creation of error (see CaptureGoroutineID and CaptureTimestamps)
+// or nil if there is nothing to capture.
+func captureMetadata() *errorMetadata {
+	goroutineID, time := captureGoroutineID(), captureTime()
+	if goroutineID == 0 && time.IsZero() {
+		return nil
+	}
+	return &errorMetadata{goroutineID: goroutineID, time: time}
 }
 
 func (extErr extendedError) InnerError() error {
@@ -85,15 +221,38 @@ func (extErr extendedError) InnerError() error {
 	return result
 }
 func (extErr extendedError) Error() string {
-	return extErr.originalError.Error()
+	message := extErr.originalError.Error()
+	if note := extErr.metadata().note; note != "" {
+		message += " (" + note + ")"
+	}
+	if IncludeInnerInError && extErr.innerError != nil && !extErr.masked && !extErr.isInnerInMessage() {
+		return message + ": " + extErr.innerError.Error()
+	}
+	return message
+}
+// isInnerInMessage checks if the inner error is already a part of the message of the original error:
+// either it is marked so (see Errorf) or it is the inner error of the original error itself (like reason of ErrWithReason).
+func (extErr extendedError) isInnerInMessage() bool {
+	if extErr.innerInMsg {
+		return true
+	}
+	if originalCompositeError, isOriginalCompositeError := extErr.originalError.(CompositeError); isOriginalCompositeError {
+		originalInner := originalCompositeError.InnerError()
+		return originalInner != nil && originalInner.Error() == extErr.innerError.Error()
+	}
+	return false
 }
 func (extErr extendedError) Location() string {
-	// %n is implemented by stack.Call
-	//noinspection GoPlaceholderCount
-	return fmt.Sprintf("%+v (%n)", extErr.location, extErr.location)
+	return formatFrame(callToFrame(extErr.location))
 }
 func (extErr extendedError) StackTrace() string {
-	return StackTraceToString(extErr.stackTrace)
+	return FramesToString(extErr.StackFrames())
+}
+func (extErr extendedError) StackFrames() []Frame {
+	if extErr.lazyStack != nil {
+		return trimFrames(extErr.lazyStack.Frames())
+	}
+	return StackTraceToFrames(extErr.stackTrace)
 }
 func (extErr extendedError) OriginalError() error {
 	originalError := extErr.originalError
@@ -103,17 +262,70 @@ func (extErr extendedError) OriginalError() error {
 	return originalError
 }
 func (extErr extendedError) Fields() map[string]interface{} {
-	if errWithFields, isErrWithFields := extErr.OriginalError().(ErrorWithFields); isErrWithFields {
-		return errWithFields.Fields()
+	var originalFields map[string]interface{}
+	if errWithFields, isErrWithFields := extErr.originalError.(ErrorWithFields); isErrWithFields && !extErr.sealed {
+		originalFields = errWithFields.Fields()
+	}
+	if len(extErr.fields) == 0 {
+		return originalFields
+	}
+	return mergeFields(originalFields, extErr.fields)
+}
+func (extErr extendedError) Code() string {
+	if extErr.code != "" {
+		return extErr.code
+	}
+	if errWithCode, isErrWithCode := extErr.originalError.(ErrorWithCode); isErrWithCode && !extErr.sealed {
+		return errWithCode.Code()
+	}
+	return ""
+}
+// Format implements fmt.Formatter.
+// Verbs %v and %s print error message, %q prints quoted error message,
+// %+v prints full details (see GetFullDetails) and %#v prints Go-syntax representation.
+func (extErr extendedError) Format(state fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if state.Flag('+') {
+			io.WriteString(state, GetFullDetails(extErr))
+			return
+		}
+		if state.Flag('#') {
+			fmt.Fprintf(state, "&fail.extendedError{originalError:%#v, innerError:%#v, location:%q}",
+				extErr.originalError, extErr.innerError, extErr.Location())
+			return
+		}
+		io.WriteString(state, extErr.Error())
+	case 's':
+		io.WriteString(state, extErr.Error())
+	case 'q':
+		fmt.Fprintf(state, "%q", extErr.Error())
+	default:
+		fmt.Fprintf(state, "%%!%c(%s)", verb, extErr.Error())
 	}
-	return nil
+}
+// Unwrap returns wrapped errors in well defined order: the original error first, then the inner error (if any).
+// It allows errors.Is and errors.As to walk the chain, while errors.Unwrap (which supports only Unwrap() error) returns nil.
+func (extErr extendedError) Unwrap() []error {
+	if extErr.innerError == nil {
+		return []error{extErr.originalError}
+	}
+	return []error{extErr.originalError, extErr.innerError}
 }
 
 // New creates a new error that captures stack trace and location where it is created
 // and keeps information about the original error which is provided as single argument.
 // The main idea is supply original error with additional information (stack trace and location).
+// If the given error is nil then nil is returned (the same applies to all constructors wrapping an error).
 // Newly created error implements CompositeError, ErrorWithLocation, ErrorWithStackTrace.
+// It also supports errors.Is and errors.As which look through the original error.
+// Note that errors.Unwrap returns nil for it (as it unwraps to several errors, see Unwrap),
+// so use errors.Is, errors.As or Walk instead of looping over errors.Unwrap.
 func New(err error, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
 	stackSkip := 1
 	if len(additionalStackSkip) > 0 {
 		stackSkip += additionalStackSkip[0]
@@ -122,18 +334,51 @@ func New(err error, additionalStackSkip ...int) error {
 	return NewWithInner(err, nil, stackSkip)
 }
 
+// Here attaches location and stack trace of its caller to the given error without changing its message or type
+// (like New does). If the given error already has a stack trace then it is returned as is
+// to keep the original site. If the given error is nil then nil is returned.
+func Here(err error) error {
+	if errorWithStackTrace, isErrorWithStackTrace := err.(ErrorWithStackTrace); isErrorWithStackTrace && errorWithStackTrace.StackTrace() != "" {
+		return err
+	}
+	return New(err, 1)
+}
+
 // NewWithInner creates a new error that captures stack trace and location where it is created
 // and keeps information about the original error and its reason (another error).
 // The main idea is supply original error with additional information (stack trace and location)
 // and keep its reason (another error).
 // Newly created error implements CompositeError, ErrorWithLocation, ErrorWithStackTrace.
+// It also supports errors.Is and errors.As which look through the original error first and then through the inner one.
+// Like for New, errors.Unwrap returns nil for it, so use errors.Is, errors.As or Walk instead.
 func NewWithInner(err, inner error, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+	return enrich(newExtendedError(err, inner, stackSkip))
+}
+
+// NewWithFields creates a new error like New does and attaches the given fields to it.
+// Newly created error implements ErrorWithFields, its fields are merged with the fields of the original error
+// (the given fields win on conflict).
+func NewWithFields(err error, fields map[string]interface{}, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
 	stackSkip := 1
 	if len(additionalStackSkip) > 0 {
 		stackSkip += additionalStackSkip[0]
 	}
-	call := stack.Caller(stackSkip)
-	return &extendedError{err, inner, call, stack.Trace().TrimBelow(call).TrimRuntime()}
+
+	extErr := newExtendedError(err, nil, stackSkip)
+	extErr.fields = mergeFields(fields)
+	return enrich(extErr)
 }
 
 // NewErrWithReason creates new error with reason.
@@ -141,68 +386,471 @@ func NewErrWithReason(message string, reason error) error {
 	return New(ErrWithReason{message, reason}, 1)
 }
 
+// NewErrWithReasonf creates new error with reason like NewErrWithReason does with message formatted according to the format specifier.
+func NewErrWithReasonf(format string, reason error, a ...interface{}) error {
+	return New(ErrWithReason{fmt.Sprintf(format, a...), reason}, 1)
+}
+
+// Wrap creates new error with the given message and the given error as its reason (see ErrWithReason).
+// Its message is "<message>: <err.Error()>", location and stack trace are captured at the Wrap call site.
+func Wrap(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return New(ErrWithReason{message, err}, 1)
+}
+
+// Wrapf creates new error like Wrap does with message formatted according to the format specifier.
+func Wrapf(err error, format string, a ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return New(ErrWithReason{fmt.Sprintf(format, a...), err}, 1)
+}
+
+// Annotate creates new error like New does with the given note appended to the message of the given error
+// as "<err.Error()> (<note>)". Unlike Wrap it keeps the identity of the given error: GetType and GetOriginalError
+// report the given error (or its original error), so annotations are breadcrumbs recording additional locations
+// and stack traces rather than new errors.
+func Annotate(err error, note string) error {
+	if err == nil {
+		return nil
+	}
+
+	extErr := newExtendedError(err, nil, 1)
+	extErr.writableMetadata().note = note
+	return enrich(extErr)
+}
+
+// Mask creates new error whose message is the given user-friendly message while the given error is kept as its inner error
+// (see NewWithInner), so it is still found by Is and As and printed by GetFullDetails for internal logs.
+// Unlike Wrap the message of the given error is never included (even if IncludeInnerInError is set),
+// so Error returns exactly the given message.
+// Location and stack trace are captured at the Mask call site.
+func Mask(err error, userMessage string) error {
+	if err == nil {
+		return nil
+	}
+
+	extErr := newExtendedError(errors.New(userMessage), err, 1)
+	extErr.masked = true
+	return enrich(extErr)
+}
+
 // GetInner returns inner error for the given error.
 // If given error implements CompositeError then InnerError is called and its result is returned.
-// Otherwise nil is returned.
+// Otherwise the cause found by unwrappers registered by RegisterUnwrapper (or nil) is returned.
 func GetInner(err error) error {
 	if compositeError, isCompositeError := err.(CompositeError); isCompositeError {
 		return compositeError.InnerError()
 	}
+	if err == nil {
+		return nil
+	}
 
-	return nil
+	return unwrapByRegistered(err)
+}
+
+// Cause returns the deepest error of the chain of inner errors of the given error (root cause).
+// Inner errors are followed (see GetInner) until an error without inner error is reached,
+// ErrorWrapper layers are unwrapped at each step, so the result is the raw underlying error (see GetOriginalError).
+func Cause(err error) error {
+	visited := visitedErrors{}
+	for {
+		inner := GetInner(err)
+		if inner == nil {
+			inner = GetInner(GetOriginalError(err))
+		}
+		if inner == nil || !visited.visit(err) {
+			return GetOriginalError(err)
+		}
+		err = inner
+	}
 }
 
 // GetLocation returns code line and function where error occurred.
 // If given error implements ErrorWithLocation then Location is called and its result is returned.
-// Otherwise empty string is returned.
+// Otherwise (or if the location is empty) the location of the nearest error having one is searched for
+// in the chain of the given error (the chain is walked the same way as in Is).
+// If there is no such error then empty string is returned.
 func GetLocation(err error) string {
-	if errorWithLocation, isErrorWithLocation := err.(ErrorWithLocation); isErrorWithLocation {
-		return errorWithLocation.Location()
-	}
+	var result string
+	walk(err, func(currErr error) bool {
+		if errorWithLocation, isErrorWithLocation := currErr.(ErrorWithLocation); isErrorWithLocation {
+			result = errorWithLocation.Location()
+		}
+		return result == ""
+	})
+	return result
+}
 
-	return ""
+// GetShortLocation returns the place where the nearest error created by this package found in the chain
+// of the given error (the chain is walked the same way as in Is) occurred in the short form "file.go:123"
+// (base name of the file and line) suitable for log prefixes.
+// If there is no such error then empty string is returned.
+func GetShortLocation(err error) string {
+	var result string
+	walk(err, func(currErr error) bool {
+		if extErr, isExtErr := currErr.(*extendedError); isExtErr {
+			if extErr.location.Frame() == (runtime.Frame{}) {
+				result = UnknownFrameInfo
+			} else {
+				//noinspection GoPlaceholderCount
+				result = fmt.Sprintf("%s:%d", extErr.location, extErr.location)
+			}
+		}
+		return result == ""
+	})
+	return result
+}
+
+// GetLocationFrame returns the place where the nearest error created by this package found in the chain
+// of the given error (the chain is walked the same way as in Is) occurred as a structured frame.
+// If there is no such error then false is returned.
+func GetLocationFrame(err error) (Frame, bool) {
+	var result Frame
+	found := false
+	walk(err, func(currErr error) bool {
+		if extErr, isExtErr := currErr.(*extendedError); isExtErr {
+			result = callToFrame(extErr.location)
+			found = true
+		}
+		return !found
+	})
+	return result, found
 }
 
 // GetStackTrace returns stack trace for the given error.
 // If given error implements ErrorWithStackTrace then StackTrace is called and its result is returned.
-// Otherwise empty string is returned.
+// Otherwise (or if the stack trace is empty) the stack trace of the nearest error having one is searched for
+// in the chain of the given error (the chain is walked the same way as in Is).
+// If there is no such error then empty string is returned.
 func GetStackTrace(err error) string {
-	if errorWithStackTrace, isErrorWithStackTrace := err.(ErrorWithStackTrace); isErrorWithStackTrace {
-		return errorWithStackTrace.StackTrace()
+	var result string
+	walk(err, func(currErr error) bool {
+		if errorWithStackTrace, isErrorWithStackTrace := currErr.(ErrorWithStackTrace); isErrorWithStackTrace {
+			result = errorWithStackTrace.StackTrace()
+		}
+		return result == ""
+	})
+	return result
+}
+
+// NearestWithStack returns the outermost error in the chain of the given error (the chain is walked the same way as in Is)
+// which implements ErrorWithStackTrace and has non-empty stack trace, skipping plain wrappers.
+// It is intended for choosing the primary error (and its frames) to report. If there is no such error then false is returned.
+func NearestWithStack(err error) (error, bool) {
+	var result error
+	walk(err, func(currErr error) bool {
+		if errorWithStackTrace, isErrorWithStackTrace := currErr.(ErrorWithStackTrace); isErrorWithStackTrace {
+			if errorWithStackTrace.StackTrace() != "" {
+				result = currErr
+			}
+		}
+		return result == nil
+	})
+	return result, result != nil
+}
+
+// NewWithCode creates a new error like New does and attaches the given code to it.
+// Newly created error implements ErrorWithCode.
+func NewWithCode(err error, code string, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
 	}
 
-	return ""
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	extErr := newExtendedError(err, nil, stackSkip)
+	extErr.code = code
+	return enrich(extErr)
+}
+
+// WithField returns an error with the given field added to the fields of the given error.
+// If the given error is created by this package then the result keeps its location and stack trace,
+// otherwise the given error is wrapped capturing location and stack trace of the WithField caller.
+func WithField(err error, key string, value interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	return withFields(err, map[string]interface{}{key: value}, 1)
 }
 
+// withFields adds the given fields to the fields of the given error like WithField does.
+// Stack skip is counted from the caller of withFields.
+func withFields(err error, fields map[string]interface{}, stackSkip int) error {
+	if extErr, isExtErr := err.(*extendedError); isExtErr {
+		extErrCopy := *extErr
+		extErrCopy.fields = mergeFields(extErr.fields, fields)
+		return &extErrCopy
+	}
+	return NewWithFields(err, fields, stackSkip+1)
+}
+
+// GetFields returns fields of the given error merged with fields of all errors in its chain
+// (the chain is walked the same way as in Is, but errors wrapped by errors sealed by SealMetadata are skipped).
+// Fields of outer errors win on conflict. Errors which do not implement ErrorWithFields contribute nothing.
+// If there are no fields nil is returned.
+func GetFields(err error) map[string]interface{} {
+	var result map[string]interface{}
+	walkMetadata(err, func(currErr error) bool {
+		if errWithFields, isErrWithFields := currErr.(ErrorWithFields); isErrWithFields {
+			for key, value := range errWithFields.Fields() {
+				if result == nil {
+					result = make(map[string]interface{})
+				}
+				if _, exists := result[key]; !exists {
+					result[key] = value
+				}
+			}
+		}
+		return true
+	})
+	return result
+}
+
+// GetCode returns the first code found in the chain of the given error
+// (the chain is walked the same way as in GetFields).
+// If there is no error implementing ErrorWithCode with non-empty code then empty string is returned.
+func GetCode(err error) string {
+	var result string
+	walkMetadata(err, func(currErr error) bool {
+		if errWithCode, isErrWithCode := currErr.(ErrorWithCode); isErrWithCode {
+			result = errWithCode.Code()
+		}
+		return result == ""
+	})
+	return result
+}
+
+// IncludeInnerInError makes Error of errors created by this package append message of the inner error
+// given to NewWithInner (separated by colon), so the whole chain is described by a single line.
+// Inner errors which are already a part of the message (like reason of ErrWithReason) are not appended again.
+// It is disabled by default, so Error returns the message of the original error only.
+var IncludeInnerInError = false
+
+// DetailsIndent is the indent of fields and stack trace frames in the output of GetFullDetails.
+var DetailsIndent = "    "
+
+// cycleDetectedMarker is written by GetFullDetails when the chain of inner errors is cyclic.
+const cycleDetectedMarker = "<cycle detected>"
+
 // GetFullDetails returns information about the error itself
-// and all its inner errors (and their stack traces) recursively.
+// and all its inner errors (and their fields and stack traces) recursively.
+// Values of fields with keys registered by RegisterRedactedFieldKey are redacted.
+// If the chain of inner errors is cyclic then details end with "<cycle detected>" line.
 func GetFullDetails(err error) string {
+	return getFullDetails(err, detailsOptions{indent: DetailsIndent})
+}
+
+// GetFullDetailsWithIndent returns information like GetFullDetails does using the given indent instead of DetailsIndent.
+func GetFullDetailsWithIndent(err error, indent string) string {
+	return getFullDetails(err, detailsOptions{indent: indent})
+}
+
+// GetFullDetailsCompact returns information like GetFullDetails does,
+// but frames which stack trace of inner error shares with stack trace of its outer error
+// are collapsed to a single "... N more" line.
+func GetFullDetailsCompact(err error) string {
+	return getFullDetails(err, detailsOptions{indent: DetailsIndent, compact: true})
+}
+
+// GetFullDetailsWithLocations returns information like GetFullDetails does,
+// but each error having location (see ErrorWithLocation) is preceded by "at LOCATION:" header line.
+func GetFullDetailsWithLocations(err error) string {
+	return getFullDetails(err, detailsOptions{indent: DetailsIndent, locations: true})
+}
+
+// ColorizeDetails enables ANSI colors in the output of GetFullDetailsColored.
+// It should be disabled when the output is not a terminal.
+var ColorizeDetails = true
+
+// ANSI escape codes used by GetFullDetailsColored.
+const (
+	ansiRed   = "\x1b[31m"
+	ansiDim   = "\x1b[2m"
+	ansiReset = "\x1b[0m"
+)
+
+// GetFullDetailsColored returns information like GetFullDetails does, but messages are colored red
+// and stack trace frames are dimmed using ANSI escape codes for readability in a terminal.
+// If ColorizeDetails is disabled then the output is the same as the one of GetFullDetails.
+func GetFullDetailsColored(err error) string {
+	return getFullDetails(err, detailsOptions{indent: DetailsIndent, colored: ColorizeDetails})
+}
+
+// detailsOptions defines how getFullDetails renders error details.
+type detailsOptions struct {
+	indent    string
+	compact   bool
+	locations bool
+	colored   bool
+}
+
+// color wraps the given text in the given ANSI escape code if colored output is requested.
+func (options detailsOptions) color(text, code string) string {
+	if !options.colored {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+func getFullDetails(err error, options detailsOptions) string {
 	var result bytes.Buffer
+	writeFullDetails(&result, err, options)
+	return result.String()
+}
 
-	currErr := err
-	for currErr != nil {
-		if result.Len() > 0 {
-			result.WriteString("\n")
+// WriteFullDetails writes information like GetFullDetails does directly to the given writer
+// streaming it piece by piece instead of building the whole string in memory.
+// It returns the number of bytes written and the first error returned by the writer (writing is stopped then).
+func WriteFullDetails(w io.Writer, err error) (int, error) {
+	return writeFullDetails(w, err, detailsOptions{indent: DetailsIndent})
+}
+
+// detailsWriter writes to the underlying writer counting written bytes until the first error.
+type detailsWriter struct {
+	w   io.Writer
+	n   int
+	err error
+}
+
+func (writer *detailsWriter) writeString(s string) {
+	if writer.err != nil {
+		return
+	}
+	n, err := io.WriteString(writer.w, s)
+	writer.n += n
+	writer.err = err
+}
+
+func writeFullDetails(w io.Writer, err error, options detailsOptions) (int, error) {
+	return writeChainDetails(w, err, options, visitedErrors{})
+}
+
+// writeChainDetails writes details of the given error and its inner errors (see writeFullDetails)
+// detecting cycles by the given errors visited already.
+// Errors aggregated by the error (like MultiError does) are written after it indented and numbered.
+func writeChainDetails(w io.Writer, err error, options detailsOptions, visited visitedErrors) (int, error) {
+	result := &detailsWriter{w: w}
+	var outerFrames []string
+
+	for currErr := err; currErr != nil && result.err == nil; currErr = GetInner(currErr) {
+		if result.n > 0 {
+			result.writeString("\n")
+		}
+		if !visited.visit(currErr) {
+			result.writeString(cycleDetectedMarker)
+			break
+		}
+		if goroutineID := ownGoroutineID(currErr); goroutineID != 0 {
+			result.writeString(fmt.Sprintf("goroutine %v:\n", goroutineID))
+		}
+		if errTime := ownTime(currErr); !errTime.IsZero() {
+			result.writeString(fmt.Sprintf("time %v:\n", errTime.Format(time.RFC3339Nano)))
+		}
+		if errorWithLocation, isErrorWithLocation := currErr.(ErrorWithLocation); isErrorWithLocation && options.locations {
+			if location := errorWithLocation.Location(); location != "" {
+				result.writeString(fmt.Sprintf("at %v:\n", location))
+			}
+		}
+		result.writeString(options.color(fmt.Sprintf("%v: %v", getTypeName(currErr), currErr), ansiRed))
+		if errorWithFields, isErrorWithFields := currErr.(ErrorWithFields); isErrorWithFields {
+			if fields := errorWithFields.Fields(); len(fields) > 0 {
+				result.writeString(fmt.Sprintf("\n%vfields: %v", options.indent, formatFields(RedactFields(fields))))
+			}
 		}
-		result.WriteString(fmt.Sprintf("%v: %v", GetType(currErr), currErr))
 
 		if errorWithStackTrace, isErrorWithStackTrace := currErr.(ErrorWithStackTrace); isErrorWithStackTrace {
 			stackTrace := errorWithStackTrace.StackTrace()
 			if stackTrace != "" {
-				const ident = "    "
-				result.WriteString(fmt.Sprintf("\n%v%v", ident, strings.Replace(stackTrace, "\n", "\n" + ident, -1)))
+				frames := strings.Split(stackTrace, "\n")
+				commonFramesCount := 0
+				if options.compact {
+					commonFramesCount = getCommonSuffixLength(frames, outerFrames)
+					if commonFramesCount == len(frames) {
+						// keep at least the top frame
+						commonFramesCount--
+					}
+				}
+				for _, frame := range frames[:len(frames)-commonFramesCount] {
+					result.writeString(fmt.Sprintf("\n%v%v", options.indent, options.color(frame, ansiDim)))
+				}
+				if commonFramesCount > 0 {
+					result.writeString(fmt.Sprintf("\n%v%v", options.indent, options.color(fmt.Sprintf("... %v more", commonFramesCount), ansiDim)))
+				}
+				outerFrames = frames
+			}
+		}
+
+		for i, memberErr := range getAggregatedErrors(currErr) {
+			memberVisited := visitedErrors{}
+			for key := range visited {
+				memberVisited[key] = true
+			}
+			var memberDetails strings.Builder
+			writeChainDetails(&memberDetails, memberErr, options, memberVisited)
+			for j, line := range strings.Split(memberDetails.String(), "\n") {
+				if j == 0 {
+					line = fmt.Sprintf("[%v] %v", i, line)
+				}
+				result.writeString(fmt.Sprintf("\n%v%v", options.indent, line))
 			}
 		}
+	}
+
+	return result.n, result.err
+}
 
-		currErr = GetInner(currErr)
+// getAggregatedErrors returns errors aggregated by the given error or by its original error (see ErrorWrapper)
+// like MultiError does, or nil if there are none.
+func getAggregatedErrors(err error) []error {
+	if aggregator, isAggregator := err.(interface{ Errors() []error }); isAggregator {
+		return aggregator.Errors()
 	}
+	if errorWrapper, isErrorWrapper := err.(ErrorWrapper); isErrorWrapper {
+		if aggregator, isAggregator := errorWrapper.OriginalError().(interface{ Errors() []error }); isAggregator {
+			return aggregator.Errors()
+		}
+	}
+	return nil
+}
 
+// formatFields renders fields as "key1=value1, key2=value2" sorted by keys.
+func formatFields(fields map[string]interface{}) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result bytes.Buffer
+	for i, key := range keys {
+		if i > 0 {
+			result.WriteString(", ")
+		}
+		result.WriteString(fmt.Sprintf("%v=%v", key, fields[key]))
+	}
 	return result.String()
 }
 
+// getCommonSuffixLength returns number of equal trailing elements of the given slices.
+func getCommonSuffixLength(slice1, slice2 []string) int {
+	result := 0
+	for result < len(slice1) && result < len(slice2) && slice1[len(slice1)-1-result] == slice2[len(slice2)-1-result] {
+		result++
+	}
+	return result
+}
+
 // GetType returns the type of the original error.
 // If provided error implements ErrorWrapper then GetType is run for its original error
 // until first non-ErrorWrapper is found.
+// Errors created by Wrap and NewErrWithReason are of type ErrWithReason, use GetType(Cause(err)) to get the type of the reason.
 func GetType(err error) reflect.Type {
 	var errToGetTypeOf error
 	if errorWrapper, isErrorWrapper := err.(ErrorWrapper); isErrorWrapper {
@@ -222,27 +870,133 @@ func GetOriginalError(err error) error {
 	return err
 }
 
+// Sentinel creates lightweight error with the given message intended to be declared at package level
+// (like var ErrNotFound = fail.Sentinel("not found")). No location or stack trace is captured.
+// Each call returns a distinct error, so it is matched by identity (e.g. by Is even after wrapping).
+func Sentinel(message string) error {
+	return &sentinelError{message}
+}
+
+type sentinelError struct {
+	message string
+}
+
+func (err *sentinelError) Error() string {
+	return err.message
+}
+
+// Plain returns a new error with the same message as the given error but without any additional information
+// (location, stack trace, fields, inner errors, etc.), e.g. to not leak internal details across API boundaries.
+// If the given error is nil then nil is returned.
+func Plain(err error) error {
+	if err == nil {
+		return nil
+	}
+	return errors.New(err.Error())
+}
+
 // News creates new error from text.
-func News(text string) error {
-	return New(errors.New(text), 1)
+// Optional additional stack skip works like in New.
+func News(text string, additionalStackSkip ...int) error {
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+	return New(errors.New(text), stackSkip)
 }
 
 // Newf creates new error from formatted text.
+// Use NewfWithStackSkip to specify additional stack skip.
 func Newf(format string, a ...interface{}) error {
 	return New(fmt.Errorf(format, a...), 1)
 }
 
+// NewfWithStackSkip creates new error from formatted text like Newf does with additional stack skip working like in New
+// (Newf cannot accept it as its arguments are variadic already).
+func NewfWithStackSkip(additionalStackSkip int, format string, a ...interface{}) error {
+	return New(fmt.Errorf(format, a...), 1+additionalStackSkip)
+}
+
+// Errorf creates new error from formatted text like fmt.Errorf does.
+// Errors given for %w verbs become inner error of the created error (joined by Join if there are several of them),
+// so they are found by Is and As. If there is no %w verb then Errorf works like Newf.
+func Errorf(format string, a ...interface{}) error {
+	formattedErr := fmt.Errorf(format, a...)
+	var wrappedErrs []error
+	switch unwrapper := formattedErr.(type) {
+	case interface{ Unwrap() []error }:
+		wrappedErrs = unwrapper.Unwrap()
+	case interface{ Unwrap() error }:
+		wrappedErrs = []error{unwrapper.Unwrap()}
+	}
+	if len(wrappedErrs) == 0 {
+		return New(formattedErr, 1)
+	}
+
+	extErr := newExtendedError(errors.New(formattedErr.Error()), Join(wrappedErrs...), 1)
+	extErr.innerInMsg = true
+	return enrich(extErr)
+}
+
+// GetStackFrames returns stack trace for the given error as frames.
+// If given error implements ErrorWithStackFrames then StackFrames is called and its result is returned.
+// Otherwise nil is returned.
+func GetStackFrames(err error) []Frame {
+	if errorWithStackFrames, isErrorWithStackFrames := err.(ErrorWithStackFrames); isErrorWithStackFrames {
+		return errorWithStackFrames.StackFrames()
+	}
+
+	return nil
+}
+
+// OriginatedIn checks if any error in the chain of the given error (the chain is walked the same way as in Is)
+// was created in the package with the given path or below it in the call stack, i.e. if any frame of its stack trace
+// (or its location if there is no stack trace) belongs to the package. It allows to attribute errors to subsystems.
+func OriginatedIn(err error, pkgPath string) bool {
+	found := false
+	walk(err, func(currErr error) bool {
+		frames := GetStackFrames(currErr)
+		if extErr, isExtErr := currErr.(*extendedError); isExtErr && len(frames) == 0 {
+			frames = []Frame{callToFrame(extErr.location)}
+		}
+		for _, frame := range frames {
+			if frame.Package == pkgPath {
+				found = true
+				break
+			}
+		}
+		return !found
+	})
+	return found
+}
+
 // StackTraceToString converts stack trace in string representation.
 func StackTraceToString(stackTrace stack.CallStack) string {
+	return FramesToString(StackTraceToFrames(stackTrace))
+}
+
+// StackTraceToFrames converts stack trace in frames.
+// Frames of packages registered by AddStackTrimPrefix are dropped.
+func StackTraceToFrames(stackTrace stack.CallStack) []Frame {
+	if len(stackTrace) == 0 {
+		return nil
+	}
+
+	result := make([]Frame, len(stackTrace))
+	for i, call := range stackTrace {
+		result[i] = callToFrame(call)
+	}
+	return trimFrames(result)
+}
+
+// FramesToString converts frames in string representation where each frame is on its own line (see StackFormatter).
+func FramesToString(frames []Frame) string {
 	var result bytes.Buffer
-	for _, call := range stackTrace {
+	for _, frame := range frames {
 		if result.Len() > 0 {
 			result.WriteString("\n")
 		}
-
-		// %n is implemented by stack.Call
-		//noinspection GoPlaceholderCount
-		result.WriteString(fmt.Sprintf("%+v (%n)", call, call))
+		result.WriteString(formatFrame(frame))
 	}
 	return result.String()
 }
@@ -255,50 +1009,70 @@ func StackTrace(additionalStackSkip ...int) string {
 		stackSkip += additionalStackSkip[0]
 	}
 
-	call := stack.Caller(stackSkip)
-	stackTrace := stack.Trace().TrimBelow(call).TrimRuntime()
-	return StackTraceToString(stackTrace)
+	return StackTraceN(stackSkip, 0)
+}
+
+// StackTraceN returns at most maxFrames frames from the top of current stack trace.
+// Parameter skip defines how many closest callers skip. Zero or negative maxFrames means no limit (like StackTrace does).
+func StackTraceN(skip, maxFrames int) string {
+	call := stack.Caller(skip + 1)
+	frames := StackTraceToFrames(stack.Trace().TrimBelow(call).TrimRuntime())
+	if maxFrames > 0 && len(frames) > maxFrames {
+		frames = frames[:maxFrames]
+	}
+	return FramesToString(frames)
 }
 
 // IsError check if the first argument error is the same instance as the second argument error.
 // If the first error is CompositeError than IsError is called recursively for CompositeError.InnerError().
+// Is should be preferred as it also walks the wrapper chain and honors custom Is methods.
 func IsError(whereToFind, errToFind error) bool {
-	if whereToFind == errToFind {
-		return true
-	}
+	visited := visitedErrors{}
+	for {
+		if whereToFind == errToFind {
+			return true
+		}
 
-	if compositeError, isCompositeError := whereToFind.(CompositeError); isCompositeError {
-		return IsError(compositeError.InnerError(), errToFind)
+		compositeError, isCompositeError := whereToFind.(CompositeError)
+		if !isCompositeError || !visited.visit(whereToFind) {
+			return false
+		}
+		whereToFind = compositeError.InnerError()
 	}
-
-	return false
 }
 
 // GetErrorByType returns error if desired type.
+// Types of the original errors are compared (see AreErrorsOfEqualType) and the original error is returned
+// (see GetOriginalError), not the error created by this package which wraps it, so the result can be type asserted.
 func GetErrorByType(whereToFind, errExampleToFind error) error {
-	if AreErrorsOfEqualType(whereToFind, errExampleToFind) {
-		return whereToFind
-	}
+	visited := visitedErrors{}
+	for {
+		if AreErrorsOfEqualType(whereToFind, errExampleToFind) {
+			return GetOriginalError(whereToFind)
+		}
 
-	if compositeError, isCompositeError := whereToFind.(CompositeError); isCompositeError {
-		return GetErrorByType(compositeError.InnerError(), errExampleToFind)
+		compositeError, isCompositeError := whereToFind.(CompositeError)
+		if !isCompositeError || !visited.visit(whereToFind) {
+			return nil
+		}
+		whereToFind = compositeError.InnerError()
 	}
-
-	return nil
 }
 
 // AreErrorsOfEqualType checks if 2 errors are of the same type.
+// Types of the original errors are compared (see GetType), so an error created by this package from *MyError
+// is of the same type as *MyError. Pointer and value of the same type are considered to be of the same type.
 // Always returns false if one of the arguments is nil.
 func AreErrorsOfEqualType(err1, err2 error) bool {
 	if err1 == nil || err2 == nil {
 		return false
 	}
 
-	err1Type := reflect.TypeOf(err1)
+	err1Type := GetType(err1)
 	if err1Type.Kind() == reflect.Ptr {
 		err1Type = err1Type.Elem()
 	}
-	err2Type := reflect.TypeOf(err2)
+	err2Type := GetType(err2)
 	if err2Type.Kind() == reflect.Ptr {
 		err2Type = err2Type.Elem()
 	}
@@ -306,4 +1080,137 @@ func AreErrorsOfEqualType(err1, err2 error) bool {
 		return true
 	}
 	return false;
-}
\ No newline at end of file
+}
+
+// newExtendedError creates extended error capturing location and stack trace of the caller
+// which is stackSkip frames above the caller of newExtendedError.
+func newExtendedError(err, inner error, stackSkip int) *extendedError {
+	extErr := &extendedError{}
+	initExtendedError(extErr, err, inner, stackSkip+1)
+	return extErr
+}
+
+// initExtendedError initializes the given zero extended error capturing location and stack trace of the caller
+// which is stackSkip frames above the caller of initExtendedError.
+func initExtendedError(extErr *extendedError, err, inner error, stackSkip int) {
+	call, stackTrace := captureStack(stackSkip + 1)
+	extErr.originalError = err
+	extErr.innerError = inner
+	extErr.location = call
+	extErr.stackTrace = stackTrace
+	extErr.meta = captureMetadata()
+}
+
+// stackCapturer is the function set by SetStackCapturer or nil if default capturing is used.
+var stackCapturer func(skip int) (stack.Call, stack.CallStack)
+
+// SetStackCapturer replaces the function capturing location and stack trace of errors created by this package
+// (including errors created by NewLazy, NewLocationOnly, NewTrimmedAbove and Recover).
+// The function gets the number of frames to skip above its caller and returns location and stack trace.
+// It is intended for tests which need deterministic frames (e.g. tests of formatting) and is not safe for concurrent use.
+// Nil value restores default capturing.
+func SetStackCapturer(capturer func(skip int) (stack.Call, stack.CallStack)) {
+	stackCapturer = capturer
+}
+
+// captureStack returns location and stack trace of the caller which is skip frames above the caller of captureStack.
+// It captures them (honoring CaptureStackTraces) using stack package unless replaced by SetStackCapturer.
+func captureStack(skip int) (stack.Call, stack.CallStack) {
+	if stackCapturer != nil {
+		return stackCapturer(skip + 1)
+	}
+	call := stack.Caller(skip + 1)
+	return call, captureStackTrace(call)
+}
+
+// captureLocation returns location of the caller which is skip frames above the caller of captureLocation
+// like captureStack does, but without capturing stack trace unless replaced by SetStackCapturer.
+func captureLocation(skip int) stack.Call {
+	if stackCapturer != nil {
+		call, _ := stackCapturer(skip + 1)
+		return call
+	}
+	return stack.Caller(skip + 1)
+}
+
+// captureStackTrace returns current stack trace starting from the given call limited by MaxStackDepth.
+// It returns nil if CaptureStackTraces is disabled without calling stack.Trace,
+// so errors created then cost only capturing of their location (see BenchmarkNewWithoutStackTraces).
+func captureStackTrace(call stack.Call) stack.CallStack {
+	if !CaptureStackTraces {
+		return nil
+	}
+	return limitStackTrace(stack.Trace().TrimBelow(call).TrimRuntime())
+}
+
+// limitStackTrace returns the given stack trace limited by MaxStackDepth.
+func limitStackTrace(stackTrace stack.CallStack) stack.CallStack {
+	if MaxStackDepth > 0 && len(stackTrace) > MaxStackDepth {
+		// copy to not retain the whole captured stack trace
+		stackTrace = append(stack.CallStack(nil), stackTrace[:MaxStackDepth]...)
+	}
+	return stackTrace
+}
+
+// formatFrame renders frame using StackFormatter.
+func formatFrame(frame Frame) string {
+	if StackFormatter == nil {
+		return DefaultStackFormatter(frame)
+	}
+	return StackFormatter(frame)
+}
+
+// callToFrame converts stack.Call to Frame.
+func callToFrame(call stack.Call) Frame {
+	runtimeFrame := call.Frame()
+	if runtimeFrame == (runtime.Frame{}) {
+		// stack.Call renders such frame as "%!s(NOFUNC)"
+		return markUnknownFrameInfo(Frame{}, runtimeFrame)
+	}
+
+	// %n is implemented by stack.Call
+	//noinspection GoPlaceholderCount
+	line, _ := strconv.Atoi(fmt.Sprintf("%d", call))
+	return markUnknownFrameInfo(Frame{
+		File:     fmt.Sprintf("%+s", call),
+		Line:     line,
+		Function: fmt.Sprintf("%n", call),
+		Package:  getFunctionPackage(fmt.Sprintf("%+n", call)),
+	}, runtimeFrame)
+}
+
+// markUnknownFrameInfo sets file and function of the given frame which are missing in the given runtime frame
+// to UnknownFrameInfo, so such frames are rendered consistently.
+func markUnknownFrameInfo(frame Frame, runtimeFrame runtime.Frame) Frame {
+	if runtimeFrame.File == "" || runtimeFrame.File == "?" {
+		frame.File = UnknownFrameInfo
+	}
+	if runtimeFrame.Function == "" {
+		frame.Function = UnknownFrameInfo
+	}
+	return frame
+}
+
+// getFunctionPackage returns the import path of the package of the function with the given qualified name.
+func getFunctionPackage(functionName string) string {
+	lastSep := strings.LastIndex(functionName, "/")
+	if i := strings.Index(functionName[lastSep+1:], "."); i != -1 {
+		return functionName[:lastSep+1+i]
+	}
+	return functionName
+}
+
+// mergeFields returns a new map containing fields from all given maps, fields of the latter maps win on conflict.
+// If there are no fields nil is returned.
+func mergeFields(fieldMaps ...map[string]interface{}) map[string]interface{} {
+	var result map[string]interface{}
+	for _, fields := range fieldMaps {
+		for key, value := range fields {
+			if result == nil {
+				result = make(map[string]interface{})
+			}
+			result[key] = value
+		}
+	}
+	return result
+}
@@ -199,4 +199,1231 @@ func TestFail(t *testing.T) {
 		So(fields, ShouldBeNil)
 	});
 
-}
\ No newline at end of file
+
+	Convey("Standard errors.Is() and errors.As()", t, func() {
+		sentinel := errors.New("sentinel")
+		Convey("should find reason of ErrWithReason", func() {
+			So(errors.Is(fail.NewErrWithReason("x", sentinel), sentinel), ShouldBeTrue)
+		})
+		Convey("should find original error", func() {
+			So(errors.Is(fail.New(sentinel), sentinel), ShouldBeTrue)
+		})
+		Convey("should find inner error", func() {
+			So(errors.Is(fail.NewWithInner(fail.News("outer"), fail.New(sentinel)), sentinel), ShouldBeTrue)
+		})
+		Convey("should not find absent error", func() {
+			So(errors.Is(fail.NewWithInner(fail.News("outer"), fail.News("inner")), sentinel), ShouldBeFalse)
+		})
+		Convey("should unwrap original first, then inner", func() {
+			original := errors.New("original")
+			err := fail.NewWithInner(original, sentinel)
+			unwrapped := err.(interface{ Unwrap() []error }).Unwrap()
+			So(unwrapped, ShouldResemble, []error{original, sentinel})
+		})
+		Convey("should not be unwrapped by errors.Unwrap", func() {
+			So(errors.Unwrap(fail.New(sentinel)), ShouldBeNil)
+			So(errors.Unwrap(fail.NewWithInner(fail.News("outer"), sentinel)), ShouldBeNil)
+		})
+		Convey("should extract typed error", func() {
+			err := fail.NewErrWithReason("x", fail.New(&MyError{msg: "my"}))
+			var myErr *MyError
+			So(errors.As(err, &myErr), ShouldBeTrue)
+			So(myErr.msg, ShouldEqual, "my")
+		})
+	})
+
+	Convey("Is()", t, func() {
+		sentinel := errors.New("sentinel")
+		Convey("should find sentinel wrapped twice", func() {
+			err := fail.NewErrWithReason("outer", fail.NewErrWithReason("inner", sentinel))
+			So(fail.Is(err, sentinel), ShouldBeTrue)
+		})
+		Convey("should find sentinel through wrapper chain", func() {
+			So(fail.Is(fail.New(fail.New(sentinel)), sentinel), ShouldBeTrue)
+		})
+		Convey("should find sentinel through inner chain", func() {
+			err := fail.NewWithInner(fail.News("outer"), &MyError{"my", sentinel})
+			So(fail.Is(err, sentinel), ShouldBeTrue)
+		})
+		Convey("should honor custom Is method", func() {
+			err := fail.NewErrWithReason("outer", MyErrWithIs{"code1"})
+			So(fail.Is(err, MyErrWithIs{"code1"}), ShouldBeTrue)
+			So(fail.Is(err, &MyErrWithIs{"code1"}), ShouldBeTrue)
+			So(fail.Is(err, MyErrWithIs{"code2"}), ShouldBeFalse)
+		})
+		Convey("should return false when target is absent", func() {
+			So(fail.Is(fail.News("test"), sentinel), ShouldBeFalse)
+		})
+		Convey("should handle nils", func() {
+			So(fail.Is(nil, nil), ShouldBeTrue)
+			So(fail.Is(nil, sentinel), ShouldBeFalse)
+			So(fail.Is(sentinel, nil), ShouldBeFalse)
+		})
+	})
+	Convey("As()", t, func() {
+		myErr := &MyError{"my", errors.New("reason")}
+		err := fail.NewWithInner(fail.News("outer"), fail.NewErrWithReason("middle", fail.New(myErr)))
+		Convey("should extract error deep inside composite chain", func() {
+			var target *MyError
+			So(fail.As(err, &target), ShouldBeTrue)
+			So(target, ShouldEqual, myErr)
+		})
+		Convey("should extract error to interface", func() {
+			var target fail.ErrorWithStackTrace
+			So(fail.As(err, &target), ShouldBeTrue)
+			So(target, ShouldEqual, err)
+		})
+		Convey("should return false when there is no error of desired type", func() {
+			var target MyErrWithFields
+			So(fail.As(err, &target), ShouldBeFalse)
+		})
+		Convey("should panic on invalid target", func() {
+			So(func() { fail.As(err, nil) }, ShouldPanic)
+			So(func() { fail.As(err, MyError{}) }, ShouldPanic)
+			So(func() { fail.As(err, (*MyError)(nil)) }, ShouldPanic)
+			So(func() { fail.As(err, new(string)) }, ShouldPanic)
+		})
+	})
+
+	Convey("NewWithFields() and GetFields()", t, func() {
+		inner := fail.NewWithFields(errors.New("inner"), map[string]interface{}{"a": 1, "b": 1})
+		err := fail.NewWithInner(fail.NewWithFields(fail.News("outer"), map[string]interface{}{"b": 2, "c": 2}), inner)
+		Convey("should implement ErrorWithFields", func() {
+			So(inner.(fail.ErrorWithFields).Fields(), ShouldResemble, map[string]interface{}{"a": 1, "b": 1})
+		})
+		Convey("should have correct location", func() {
+			So(fail.GetLocation(inner), ShouldContainSubstring, "fail_test.go")
+		})
+		Convey("should merge fields of whole chain with outer keys winning", func() {
+			So(fail.GetFields(err), ShouldResemble, map[string]interface{}{"a": 1, "b": 2, "c": 2})
+		})
+		Convey("should merge fields of original error", func() {
+			err := fail.NewWithFields(MyErrWithFields{"p1", "p2"}, map[string]interface{}{"param2": "new", "x": 1})
+			So(fail.GetFields(err), ShouldResemble, map[string]interface{}{"param1": "p1", "param2": "new", "x": 1})
+			So(fail.GetFields(fail.New(err)), ShouldResemble, map[string]interface{}{"param1": "p1", "param2": "new", "x": 1})
+		})
+		Convey("should not get fields from errors without fields", func() {
+			So(fail.GetFields(errors.New("test")), ShouldBeNil)
+			So(fail.GetFields(fail.NewErrWithReason("test", fail.News("test"))), ShouldBeNil)
+			So(fail.GetFields(nil), ShouldBeNil)
+		})
+	})
+
+	Convey("WithField()", t, func() {
+		original := fail.News("test")
+		err := fail.WithField(fail.WithField(original, "a", 1), "b", 2)
+		Convey("should yield all fields", func() {
+			So(fail.GetFields(err), ShouldResemble, map[string]interface{}{"a": 1, "b": 2})
+		})
+		Convey("should not modify original error", func() {
+			So(fail.GetFields(original), ShouldBeNil)
+		})
+		Convey("should preserve location and stack trace of the first wrap", func() {
+			So(fail.GetLocation(err), ShouldEqual, fail.GetLocation(original))
+			So(fail.GetStackTrace(err), ShouldEqual, fail.GetStackTrace(original))
+			So(fail.GetOriginalError(err), ShouldEqual, fail.GetOriginalError(original))
+		})
+		Convey("should wrap error not created by fail", func() {
+			plainErr := errors.New("plain")
+			err := fail.WithField(plainErr, "a", 1)
+			So(fail.GetFields(err), ShouldResemble, map[string]interface{}{"a": 1})
+			So(fail.GetOriginalError(err), ShouldEqual, plainErr)
+			So(strings.Split(fail.GetStackTrace(err), "\n")[0], ShouldContainSubstring, "fail_test.go")
+		})
+	})
+
+	Convey("GetStackFrames()", t, func() {
+		err := fail.News("test")
+		line := fail.GetStackFrames(fail.News("next line"))[0].Line
+		Convey("should have frame for creation site", func() {
+			frames := fail.GetStackFrames(err)
+			So(len(frames), ShouldBeGreaterThan, 1)
+			So(fileName(frames[0]), ShouldEqual, "fail_test.go")
+			So(frames[0].Package, ShouldEqual, "github.com/nbgo/fail_test")
+			So(frames[0].Line, ShouldEqual, line-1)
+			So(frames[0].Function, ShouldStartWith, "TestFail.")
+		})
+		Convey("should be consistent with string stack trace", func() {
+			So(fail.FramesToString(fail.GetStackFrames(err)), ShouldEqual, fail.GetStackTrace(err))
+			So(fail.GetStackFrames(err)[0].String(), ShouldEqual, fail.GetLocation(err))
+		})
+		Convey("should return nil for error without stack trace", func() {
+			So(fail.GetStackFrames(errors.New("test")), ShouldBeNil)
+		})
+	})
+
+	Convey("MaxStackDepth", t, func() {
+		Convey("should limit number of retained frames", func() {
+			fail.MaxStackDepth = 3
+			defer func() { fail.MaxStackDepth = 0 }()
+			err := fail.NewWithInner(fail.News("outer"), fail.News("inner"))
+			So(len(fail.GetStackFrames(err)), ShouldBeBetweenOrEqual, 1, 3)
+			So(len(fail.GetStackFrames(fail.GetInner(err))), ShouldBeBetweenOrEqual, 1, 3)
+			So(fileName(fail.GetStackFrames(err)[0]), ShouldEqual, "fail_test.go")
+		})
+		Convey("should be unlimited by default", func() {
+			So(len(fail.GetStackFrames(fail.News("test"))), ShouldBeGreaterThan, 3)
+		})
+	})
+
+	Convey("NewWithCode() and GetCode()", t, func() {
+		codedErr := fail.NewWithCode(errors.New("not found"), "NOT_FOUND")
+		Convey("should implement ErrorWithCode", func() {
+			So(codedErr.(fail.ErrorWithCode).Code(), ShouldEqual, "NOT_FOUND")
+			So(codedErr.Error(), ShouldEqual, "not found")
+		})
+		Convey("should keep code after wrapping twice", func() {
+			So(fail.GetCode(fail.New(fail.New(codedErr))), ShouldEqual, "NOT_FOUND")
+			So(fail.New(fail.New(codedErr)).(fail.ErrorWithCode).Code(), ShouldEqual, "NOT_FOUND")
+			So(fail.GetCode(fail.NewErrWithReason("outer", fail.NewErrWithReason("middle", codedErr))), ShouldEqual, "NOT_FOUND")
+		})
+		Convey("should return the outermost code", func() {
+			So(fail.GetCode(fail.NewWithCode(codedErr, "OUTER")), ShouldEqual, "OUTER")
+		})
+		Convey("should return empty code when there is no code", func() {
+			So(fail.GetCode(fail.News("test")), ShouldBeEmpty)
+			So(fail.GetCode(errors.New("test")), ShouldBeEmpty)
+		})
+	})
+
+	Convey("fmt.Formatter", t, func() {
+		err := fail.NewWithInner(fail.News("outer"), fail.News("inner"))
+		Convey("%v and %s should print message", func() {
+			So(fmt.Sprintf("%v", err), ShouldEqual, "outer")
+			So(fmt.Sprintf("%s", err), ShouldEqual, "outer")
+		})
+		Convey("%q should print quoted message", func() {
+			So(fmt.Sprintf("%q", err), ShouldEqual, `"outer"`)
+		})
+		Convey("%+v should print stack traces", func() {
+			output := fmt.Sprintf("%+v", err)
+			So(output, ShouldEqual, fail.GetFullDetails(err))
+			So(output, ShouldStartWith, "*errors.errorString: outer\n")
+			So(output, ShouldContainSubstring, fail.GetLocation(err))
+			So(output, ShouldContainSubstring, strings.Replace(fail.GetStackTrace(err), "\n", "\n    ", -1))
+			So(output, ShouldContainSubstring, "*errors.errorString: inner\n")
+		})
+		Convey("%#v should print Go-syntax representation", func() {
+			output := fmt.Sprintf("%#v", err)
+			So(output, ShouldStartWith, "&fail.extendedError{originalError:")
+			So(output, ShouldContainSubstring, `"outer"`)
+			So(output, ShouldContainSubstring, `"inner"`)
+			So(output, ShouldContainSubstring, "fail_test.go")
+		})
+	})
+
+	Convey("Wrap() and Wrapf()", t, func() {
+		sentinel := errors.New("sentinel")
+		Convey("should compose message", func() {
+			So(fail.Wrap(sentinel, "loading config").Error(), ShouldEqual, "loading config: sentinel")
+			So(fail.Wrapf(sentinel, "loading %v", "config").Error(), ShouldEqual, "loading config: sentinel")
+		})
+		Convey("should keep wrapped error as inner", func() {
+			So(fail.GetInner(fail.Wrap(sentinel, "test")), ShouldEqual, sentinel)
+			So(fail.GetInner(fail.Wrapf(sentinel, "test")), ShouldEqual, sentinel)
+		})
+		Convey("should be found by Is", func() {
+			So(fail.Is(fail.Wrap(fail.Wrapf(sentinel, "inner %v", 1), "outer"), sentinel), ShouldBeTrue)
+			So(errors.Is(fail.Wrap(sentinel, "test"), sentinel), ShouldBeTrue)
+		})
+		Convey("should capture stack at Wrap call site", func() {
+			So(fileName(fail.GetStackFrames(fail.Wrap(sentinel, "test"))[0]), ShouldEqual, "fail_test.go")
+			So(fileName(fail.GetStackFrames(fail.Wrapf(sentinel, "test"))[0]), ShouldEqual, "fail_test.go")
+		})
+	})
+
+	Convey("Nil error", t, func() {
+		Convey("should not be wrapped by constructors", func() {
+			So(fail.New(nil), ShouldBeNil)
+			So(fail.NewWithInner(nil, fail.News("inner")), ShouldBeNil)
+			So(fail.NewWithFields(nil, map[string]interface{}{"a": 1}), ShouldBeNil)
+			So(fail.NewWithCode(nil, "CODE"), ShouldBeNil)
+			So(fail.NewWithSeverity(nil, fail.SeverityError), ShouldBeNil)
+			So(fail.NewLazy(nil), ShouldBeNil)
+			So(fail.Wrap(nil, "test"), ShouldBeNil)
+			So(fail.Wrapf(nil, "test %v", 1), ShouldBeNil)
+			So(fail.WithField(nil, "a", 1), ShouldBeNil)
+		})
+		Convey("should yield untyped nil", func() {
+			err := fail.New(nil)
+			So(err == nil, ShouldBeTrue)
+		})
+	})
+
+	Convey("GetFullDetailsCompact()", t, func() {
+		err := createErrorInHelper(fail.News("inner"))
+		compact := fail.GetFullDetailsCompact(err)
+		full := fail.GetFullDetails(err)
+		Convey("should be shorter than full details", func() {
+			So(len(compact), ShouldBeLessThan, len(full))
+		})
+		Convey("should contain unique top frames of each level", func() {
+			So(compact, ShouldContainSubstring, fail.GetStackFrames(err)[0].String())
+			So(compact, ShouldContainSubstring, fail.GetStackFrames(fail.GetInner(err))[0].String())
+			So(compact, ShouldContainSubstring, "*errors.errorString: outer\n")
+			So(compact, ShouldContainSubstring, "*errors.errorString: inner\n")
+		})
+		Convey("should collapse common frames", func() {
+			innerFramesCount := len(fail.GetStackFrames(fail.GetInner(err)))
+			So(compact, ShouldEndWith, fmt.Sprintf("\n    ... %v more", innerFramesCount-1))
+		})
+		Convey("should be equal to full details when there are no common frames", func() {
+			err := fail.News("test")
+			So(fail.GetFullDetailsCompact(err), ShouldEqual, fail.GetFullDetails(err))
+		})
+	})
+
+	Convey("Cyclic error chain", t, func() {
+		err1 := &MyCyclicError{msg: "error 1"}
+		err2 := &MyCyclicError{msg: "error 2", inner: err1}
+		err1.inner = fail.New(err2)
+		target := errors.New("absent")
+		Convey("should not hang GetFullDetails", func() {
+			details := fail.GetFullDetails(err1)
+			So(details, ShouldContainSubstring, "error 2")
+			So(details, ShouldEndWith, "\n<cycle detected>")
+		})
+		Convey("should not hang IsError and GetErrorByType", func() {
+			So(fail.IsError(err1, target), ShouldBeFalse)
+			So(fail.IsError(err2, err1), ShouldBeTrue)
+			So(fail.GetErrorByType(err1, MyError{}), ShouldBeNil)
+		})
+		Convey("should not hang chain walking functions", func() {
+			So(fail.Is(err1, target), ShouldBeFalse)
+			var myErr *MyError
+			So(fail.As(err1, &myErr), ShouldBeFalse)
+			So(fail.GetFields(err1), ShouldBeNil)
+			So(fail.GetCode(err1), ShouldBeEmpty)
+		})
+	})
+
+	Convey("Cause()", t, func() {
+		sentinel := errors.New("sentinel")
+		Convey("should return the deepest error of three-level chain", func() {
+			err := fail.NewErrWithReason("level 1", fail.NewErrWithReason("level 2", fail.NewErrWithReason("level 3", sentinel)))
+			So(fail.Cause(err), ShouldEqual, sentinel)
+		})
+		Convey("should unwrap wrapper layers", func() {
+			err := fail.NewErrWithReason("level 1", fail.New(fail.New(sentinel)))
+			So(fail.Cause(err), ShouldEqual, sentinel)
+			So(fail.Cause(fail.NewWithInner(fail.News("outer"), fail.New(sentinel))), ShouldEqual, sentinel)
+		})
+		Convey("should return the error itself when there is no inner", func() {
+			So(fail.Cause(sentinel), ShouldEqual, sentinel)
+			So(fail.Cause(fail.New(sentinel)), ShouldEqual, sentinel)
+			So(fail.Cause(nil), ShouldBeNil)
+		})
+	})
+
+	Convey("StackFormatter", t, func() {
+		err := fail.News("test")
+		Convey("should be used to render stack traces and locations", func() {
+			fail.StackFormatter = func(frame fail.Frame) string {
+				return fmt.Sprintf("%v:%v\t%v", frame.File, frame.Line, frame.Function)
+			}
+			defer func() { fail.StackFormatter = fail.DefaultStackFormatter }()
+			frame := fail.GetStackFrames(err)[0]
+			So(fileName(frame), ShouldEqual, "fail_test.go")
+			expectedLine := fmt.Sprintf("%v:%v\t%v", frame.File, frame.Line, frame.Function)
+			So(strings.Split(fail.GetStackTrace(err), "\n")[0], ShouldEqual, expectedLine)
+			So(fail.GetLocation(err), ShouldEqual, expectedLine)
+			So(fail.StackTrace(), ShouldContainSubstring, "\t")
+		})
+		Convey("should use default format when it is nil", func() {
+			fail.StackFormatter = nil
+			defer func() { fail.StackFormatter = fail.DefaultStackFormatter }()
+			So(fail.GetLocation(err), ShouldEqual, fail.GetStackFrames(err)[0].String())
+		})
+	})
+
+	Convey("Here()", t, func() {
+		Convey("should attach stack trace to error without it", func() {
+			originalErr := errors.New("third-party error")
+			err := fail.Here(originalErr)
+			So(err.Error(), ShouldEqual, "third-party error")
+			So(fail.GetOriginalError(err), ShouldEqual, originalErr)
+			So(fail.GetType(err), ShouldEqual, reflect.TypeOf(originalErr))
+			So(fileName(fail.GetStackFrames(err)[0]), ShouldEqual, "fail_test.go")
+			So(fail.GetStackFrames(err)[0].Package, ShouldEqual, "github.com/nbgo/fail_test")
+		})
+		Convey("should be no-op for error with stack trace", func() {
+			err := createErrorInHelper(nil)
+			So(fail.Here(err), ShouldEqual, err)
+			So(fail.GetStackFrames(fail.Here(err))[0].Function, ShouldEqual, "createErrorInHelper")
+		})
+		Convey("should return nil for nil error", func() {
+			So(fail.Here(nil), ShouldBeNil)
+		})
+	})
+
+	Convey("Sentinel()", t, func() {
+		Convey("should have message and no stack trace", func() {
+			So(errNotFound.Error(), ShouldEqual, "not found")
+			So(fail.GetStackTrace(errNotFound), ShouldBeEmpty)
+			So(fail.GetLocation(errNotFound), ShouldBeEmpty)
+		})
+		Convey("should be matched by identity after wrapping", func() {
+			So(fail.Is(fail.Wrap(errNotFound, "loading user"), errNotFound), ShouldBeTrue)
+			So(fail.Is(fail.Wrap(fail.New(errNotFound), "loading user"), errNotFound), ShouldBeTrue)
+			So(errors.Is(fail.Wrap(errNotFound, "loading user"), errNotFound), ShouldBeTrue)
+		})
+		Convey("should not match another sentinel with the same message", func() {
+			So(fail.Is(fail.Wrap(errNotFound, "loading user"), fail.Sentinel("not found")), ShouldBeFalse)
+		})
+	})
+
+
+	Convey("HasMessage()", t, func() {
+		inner := errors.New("connection refused")
+		err := fail.Wrap(fail.NewWithInner(fmt.Errorf("dial failed"), inner), "cannot load user")
+
+		Convey("should find error by message of inner error", func() {
+			So(fail.HasMessage(err, "connection refused"), ShouldBeTrue)
+			So(fail.HasMessage(err, "dial failed"), ShouldBeTrue)
+			So(fail.HasMessage(err, "cannot load user: dial failed"), ShouldBeTrue)
+		})
+
+		Convey("should not match partial message", func() {
+			So(fail.HasMessage(err, "refused"), ShouldBeFalse)
+			So(fail.HasMessage(nil, ""), ShouldBeFalse)
+		})
+	})
+
+	Convey("HasMessageContaining()", t, func() {
+		err := fail.NewWithInner(errors.New("dial failed"), errors.New("connection refused"))
+
+		Convey("should find error by part of message of inner error", func() {
+			So(fail.HasMessageContaining(err, "refused"), ShouldBeTrue)
+			So(fail.HasMessageContaining(err, "timeout"), ShouldBeFalse)
+		})
+	})
+
+	Convey("NewErrWithReasonf()", t, func() {
+		reason := errors.New("connection refused")
+		line := fail.GetStackFrames(fail.News("next line"))[0].Line + 1
+		err := fail.NewErrWithReasonf("cannot load user %d", reason, 42)
+
+		Convey("should format message and keep reason as inner error", func() {
+			So(err.Error(), ShouldEqual, "cannot load user 42: connection refused")
+			So(fail.GetInner(err), ShouldEqual, reason)
+			So(fail.Is(err, reason), ShouldBeTrue)
+		})
+
+		Convey("should have location of the caller", func() {
+			So(fail.GetLocation(err), ShouldContainSubstring, fmt.Sprintf("fail_test.go:%d ", line))
+		})
+	})
+
+	Convey("ErrWithReason", t, func() {
+		reason := &MyError{msg: "my"}
+
+		Convey("GetType() should return ErrWithReason type for both bare and extended error", func() {
+			So(fail.GetType(fail.NewErrWithReason("x", reason)), ShouldEqual, reflect.TypeOf(fail.ErrWithReason{}))
+			So(fail.GetType(fail.Wrap(reason, "x")), ShouldEqual, reflect.TypeOf(fail.ErrWithReason{}))
+			So(fail.GetType(fail.ErrWithReason{"x", reason}), ShouldEqual, reflect.TypeOf(fail.ErrWithReason{}))
+		})
+
+		Convey("GetOriginalError() should return ErrWithReason rather than the reason", func() {
+			bareErr := fail.ErrWithReason{"x", reason}
+			So(fail.GetOriginalError(bareErr), ShouldResemble, bareErr)
+			So(fail.GetOriginalError(fail.NewErrWithReason("x", reason)), ShouldResemble, bareErr)
+		})
+
+		Convey("reason should be available as cause", func() {
+			err := fail.NewErrWithReason("x", reason)
+			So(fail.Cause(err), ShouldEqual, reason)
+			So(fail.GetType(fail.Cause(err)), ShouldEqual, reflect.TypeOf(reason))
+		})
+	})
+
+	Convey("Errorf()", t, func() {
+		reason := errors.New("file not found")
+
+		Convey("should wire error given for %w as inner error", func() {
+			line := fail.GetStackFrames(fail.News("next line"))[0].Line + 1
+			err := fail.Errorf("loading config %s: %w", "app.yml", reason)
+			So(err.Error(), ShouldEqual, "loading config app.yml: file not found")
+			So(fail.GetInner(err), ShouldEqual, reason)
+			So(fail.Is(err, reason), ShouldBeTrue)
+			So(errors.Is(err, reason), ShouldBeTrue)
+			So(fail.GetLocation(err), ShouldContainSubstring, fmt.Sprintf("fail_test.go:%d ", line))
+		})
+
+		Convey("should join errors given for multiple %w", func() {
+			reason2 := errors.New("permission denied")
+			err := fail.Errorf("loading config: %w, %w", reason, reason2)
+			So(err.Error(), ShouldEqual, "loading config: file not found, permission denied")
+			So(fail.Is(err, reason), ShouldBeTrue)
+			So(fail.Is(err, reason2), ShouldBeTrue)
+			So(fail.GetInner(err), ShouldHaveSameTypeAs, &fail.MultiError{})
+		})
+
+		Convey("should work like Newf without %w", func() {
+			err := fail.Errorf("loading config %s: %v", "app.yml", reason)
+			So(err.Error(), ShouldEqual, "loading config app.yml: file not found")
+			So(fail.GetInner(err), ShouldBeNil)
+			So(fail.Is(err, reason), ShouldBeFalse)
+			So(fail.GetStackTrace(err), ShouldNotBeEmpty)
+		})
+	})
+
+	Convey("GetStackTrace() and GetLocation() for wrapped extended error", t, func() {
+		extErr := fail.News("extended")
+
+		Convey("should return stack trace and location of error wrapped by ErrorWrapper", func() {
+			err := myWrapper{extErr}
+			So(fail.GetStackTrace(err), ShouldEqual, fail.GetStackTrace(extErr))
+			So(fail.GetStackTrace(err), ShouldNotBeEmpty)
+			So(fail.GetLocation(err), ShouldEqual, fail.GetLocation(extErr))
+		})
+
+		Convey("should return stack trace and location of inner error", func() {
+			err := fail.ErrWithReason{"outer", fmt.Errorf("middle: %w", extErr)}
+			So(fail.GetStackTrace(err), ShouldEqual, fail.GetStackTrace(extErr))
+			So(fail.GetLocation(err), ShouldEqual, fail.GetLocation(extErr))
+		})
+
+		Convey("should prefer stack trace of the outer error", func() {
+			err := fail.New(myWrapper{extErr})
+			So(fail.GetStackTrace(err), ShouldNotEqual, fail.GetStackTrace(extErr))
+			So(fail.GetLocation(err), ShouldNotEqual, fail.GetLocation(extErr))
+		})
+
+		Convey("should return empty string if there is no stack trace in the chain", func() {
+			So(fail.GetStackTrace(myWrapper{errors.New("plain")}), ShouldBeEmpty)
+			So(fail.GetLocation(myWrapper{errors.New("plain")}), ShouldBeEmpty)
+		})
+	})
+
+	Convey("ErrWithReason.Is()", t, func() {
+		err := fail.Wrap(fail.NewErrWithReason("loading user", errors.New("not found")), "request failed")
+
+		Convey("should match ErrWithReason with the same message ignoring reason", func() {
+			So(errors.Is(err, fail.ErrWithReason{Message: "loading user"}), ShouldBeTrue)
+			So(fail.Is(err, fail.ErrWithReason{Message: "loading user", Reason: errors.New("other")}), ShouldBeTrue)
+			So(fail.Is(err, &fail.ErrWithReason{Message: "request failed"}), ShouldBeTrue)
+		})
+
+		Convey("should not match ErrWithReason with other message", func() {
+			So(errors.Is(err, fail.ErrWithReason{Message: "loading order"}), ShouldBeFalse)
+			So(fail.ErrWithReason{Message: "x"}.Is((*fail.ErrWithReason)(nil)), ShouldBeFalse)
+			So(fail.ErrWithReason{Message: "x"}.Is(errors.New("x")), ShouldBeFalse)
+		})
+	})
+
+	Convey("ChainEqual()", t, func() {
+		newChain := func() error {
+			return fail.Wrap(fail.NewWithInner(fail.News("cannot load user"), errors.New("not found")), "request failed")
+		}
+
+		Convey("should return true for chains with equal messages and types", func() {
+			So(fail.ChainEqual(newChain(), newChain()), ShouldBeTrue)
+			So(fail.ChainEqual(fail.News("test"), errors.New("test")), ShouldBeTrue)
+			So(fail.ChainEqual(nil, nil), ShouldBeTrue)
+		})
+
+		Convey("should return false for chains of different depth", func() {
+			So(fail.ChainEqual(newChain(), fail.GetInner(newChain())), ShouldBeFalse)
+			So(fail.ChainEqual(fail.NewWithInner(fail.News("test"), errors.New("inner")), fail.News("test")), ShouldBeFalse)
+			So(fail.ChainEqual(newChain(), nil), ShouldBeFalse)
+		})
+
+		Convey("should return false for chains with different messages or types", func() {
+			So(fail.ChainEqual(fail.NewWithInner(fail.News("test"), errors.New("inner1")), fail.NewWithInner(fail.News("test"), errors.New("inner2"))), ShouldBeFalse)
+			So(fail.ChainEqual(fail.News("test"), fail.Sentinel("test")), ShouldBeFalse)
+		})
+	})
+
+	Convey("GetFullDetailsWithIndent()", t, func() {
+		err := fail.WithField(fail.News("test"), "id", 42)
+
+		Convey("should indent fields and frames with the given indent", func() {
+			details := fail.GetFullDetailsWithIndent(err, "\t")
+			lines := strings.Split(details, "\n")
+			So(lines[1], ShouldEqual, "\tfields: id=42")
+			So(lines[2], ShouldEqual, "\t"+strings.Split(fail.GetStackTrace(err), "\n")[0])
+			So(details, ShouldNotContainSubstring, "    ")
+		})
+
+		Convey("GetFullDetails() should use DetailsIndent", func() {
+			So(strings.Split(fail.GetFullDetails(err), "\n")[1], ShouldEqual, "    fields: id=42")
+			fail.DetailsIndent = "  "
+			defer func() { fail.DetailsIndent = "    " }()
+			So(fail.GetFullDetails(err), ShouldEqual, fail.GetFullDetailsWithIndent(err, "  "))
+			So(strings.Split(fail.GetFullDetailsCompact(err), "\n")[1], ShouldEqual, "  fields: id=42")
+		})
+	})
+
+	Convey("Plain()", t, func() {
+		inner := fail.News("not found")
+		err := fail.WithField(fail.Wrap(inner, "loading user"), "id", 42)
+		plainErr := fail.Plain(err)
+
+		Convey("should keep message only", func() {
+			So(plainErr.Error(), ShouldEqual, err.Error())
+			So(fail.GetStackTrace(plainErr), ShouldBeEmpty)
+			So(fail.GetLocation(plainErr), ShouldBeEmpty)
+			So(fail.GetInner(plainErr), ShouldBeNil)
+			So(fail.GetFields(plainErr), ShouldBeNil)
+			So(fail.Is(plainErr, inner), ShouldBeFalse)
+			So(errors.Unwrap(plainErr), ShouldBeNil)
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(fail.Plain(nil), ShouldBeNil)
+		})
+	})
+
+	Convey("IncludeInnerInError", t, func() {
+		err := fail.NewWithInner(fail.News("request failed"), fail.NewWithInner(fail.News("cannot load user"), errors.New("not found")))
+
+		Convey("should be disabled by default", func() {
+			So(err.Error(), ShouldEqual, "request failed")
+		})
+
+		Convey("should append messages of inner errors if enabled", func() {
+			fail.IncludeInnerInError = true
+			defer func() { fail.IncludeInnerInError = false }()
+			So(err.Error(), ShouldEqual, "request failed: cannot load user: not found")
+			So(fail.Wrap(errors.New("not found"), "cannot load user").Error(), ShouldEqual, "cannot load user: not found")
+		})
+
+		Convey("should not append inner errors which are already a part of the message", func() {
+			fail.IncludeInnerInError = true
+			defer func() { fail.IncludeInnerInError = false }()
+			notFound := errors.New("not found")
+			So(fail.Errorf("loading %s: %w", "user", notFound).Error(), ShouldEqual, "loading user: not found")
+			So(fail.NewWithInner(fail.ErrWithReason{Message: "loading user", Reason: notFound}, notFound).Error(), ShouldEqual, "loading user: not found")
+		})
+	})
+
+	Convey("GetLocationFrame()", t, func() {
+		Convey("should return location of error as frame", func() {
+			line := fail.GetStackFrames(fail.News("next line"))[0].Line + 1
+			err := fail.Wrap(myWrapper{fail.News("test")}, "wrapped")
+			frame, ok := fail.GetLocationFrame(err)
+			So(ok, ShouldBeTrue)
+			So(frame.File, ShouldEndWith, "fail_test.go")
+			So(frame.Line, ShouldEqual, line)
+			So(frame.Function, ShouldContainSubstring, "TestFail")
+			So(frame.Package, ShouldEqual, "github.com/nbgo/fail_test")
+			So(frame.String(), ShouldEqual, fail.GetLocation(err))
+		})
+
+		Convey("should return false if there is no location", func() {
+			_, ok := fail.GetLocationFrame(errors.New("plain"))
+			So(ok, ShouldBeFalse)
+			_, ok = fail.GetLocationFrame(nil)
+			So(ok, ShouldBeFalse)
+		})
+	})
+
+	Convey("News() and NewfWithStackSkip() with additional stack skip", t, func() {
+		Convey("should have location of the helper's caller", func() {
+			line := fail.GetStackFrames(fail.News("next line"))[0].Line + 1
+			errs := []error{newsInHelper("test"), newfInHelper("test %d", 42)}
+			for _, err := range errs {
+				So(fail.GetLocation(err), ShouldContainSubstring, fmt.Sprintf("fail_test.go:%d ", line))
+				So(fail.GetStackFrames(err)[0].Function, ShouldNotContainSubstring, "InHelper")
+			}
+			So(errs[1].Error(), ShouldEqual, "test 42")
+		})
+	})
+
+	Convey("Flatten()", t, func() {
+		err := fail.New(&MyError{"request failed", fail.NewErrWithReason("cannot load user", errors.New("not found"))})
+
+		Convey("should summarize all levels in single line", func() {
+			So(fail.Flatten(err), ShouldEqual, "*fail_test.MyError: MyError: request failed. Reason: cannot load user: not found"+
+				" -> fail.ErrWithReason: cannot load user: not found"+
+				" -> *errors.errorString: not found")
+		})
+
+		Convey("should use FlattenSeparator", func() {
+			fail.FlattenSeparator = " | "
+			defer func() { fail.FlattenSeparator = " -> " }()
+			So(fail.Flatten(fail.NewWithInner(fail.News("outer"), errors.New("inner"))), ShouldEqual, "*errors.errorString: outer | *errors.errorString: inner")
+		})
+
+		Convey("should return empty string for nil error", func() {
+			So(fail.Flatten(nil), ShouldBeEmpty)
+		})
+	})
+
+	Convey("CaptureStackTraces", t, func() {
+		Convey("should capture stack traces by default", func() {
+			So(fail.GetStackTrace(fail.News("test")), ShouldNotBeEmpty)
+		})
+
+		Convey("should capture location only if disabled", func() {
+			line := fail.GetStackFrames(fail.News("line of errs"))[0].Line + 3
+			fail.CaptureStackTraces = false
+			defer func() { fail.CaptureStackTraces = true }()
+			errs := []error{fail.News("test"), fail.NewWithInner(errors.New("test"), nil), fail.NewLazy(errors.New("test"))}
+			for _, err := range errs {
+				So(fail.GetStackTrace(err), ShouldBeEmpty)
+				So(fail.GetStackFrames(err), ShouldBeNil)
+				So(fail.GetLocation(err), ShouldContainSubstring, fmt.Sprintf("fail_test.go:%d ", line))
+			}
+		})
+	})
+
+	Convey("OfType()", t, func() {
+		Convey("should return error of pointer type", func() {
+			myErr := &MyError{msg: "pointer"}
+			found, ok := fail.OfType[*MyError](fail.Wrap(fail.New(myErr), "wrapped"))
+			So(ok, ShouldBeTrue)
+			So(found, ShouldEqual, myErr)
+		})
+
+		Convey("should return error of value type", func() {
+			found, ok := fail.OfType[MyError](fail.NewWithInner(fail.News("outer"), MyError{msg: "value"}))
+			So(ok, ShouldBeTrue)
+			So(found.msg, ShouldEqual, "value")
+		})
+
+		Convey("should return error implementing interface", func() {
+			found, ok := fail.OfType[interface {
+				error
+				Is(error) bool
+			}](fail.NewWithInner(fail.News("outer"), MyErrWithIs{"code1"}))
+			So(ok, ShouldBeTrue)
+			So(found, ShouldResemble, MyErrWithIs{"code1"})
+		})
+
+		Convey("should return false if there is no error of the type", func() {
+			found, ok := fail.OfType[*MyError](fail.New(MyError{msg: "value"}))
+			So(ok, ShouldBeFalse)
+			So(found, ShouldBeNil)
+			_, ok = fail.OfType[MyError](nil)
+			So(ok, ShouldBeFalse)
+		})
+	})
+
+	Convey("IsAny() and IsAll()", t, func() {
+		errA, errB, errC := fail.Sentinel("a"), fail.Sentinel("b"), fail.Sentinel("c")
+
+		Convey("should match simple chain", func() {
+			err := fail.Wrap(errA, "wrapped")
+			So(fail.IsAny(err, errB, errA), ShouldBeTrue)
+			So(fail.IsAny(err, errB, errC), ShouldBeFalse)
+			So(fail.IsAny(err), ShouldBeFalse)
+			So(fail.IsAll(err, errA), ShouldBeTrue)
+			So(fail.IsAll(err, errA, errB), ShouldBeFalse)
+			So(fail.IsAll(err), ShouldBeTrue)
+		})
+
+		Convey("should match joined errors", func() {
+			err := fail.Wrap(fail.Join(errA, fail.New(errB)), "batch failed")
+			So(fail.IsAny(err, errC, errB), ShouldBeTrue)
+			So(fail.IsAll(err, errA, errB), ShouldBeTrue)
+			So(fail.IsAll(err, errA, errB, errC), ShouldBeFalse)
+		})
+	})
+
+	Convey("GetFullDetailsWithLocations()", t, func() {
+		inner := fail.News("inner")
+		err := fail.NewWithInner(fail.News("outer"), fail.NewErrWithReason("middle", inner))
+
+		Convey("should precede each error having location with location line", func() {
+			lines := strings.Split(fail.GetFullDetailsWithLocations(err), "\n")
+			var locationLines []string
+			for i, line := range lines {
+				if strings.HasPrefix(line, "at ") {
+					locationLines = append(locationLines, line)
+					So(lines[i+1], ShouldNotStartWith, " ")
+				}
+			}
+			So(locationLines, ShouldResemble, []string{
+				"at " + fail.GetLocation(err) + ":",
+				"at " + fail.GetLocation(fail.GetInner(err)) + ":",
+				"at " + fail.GetLocation(inner) + ":",
+			})
+		})
+
+		Convey("should otherwise be equal to GetFullDetails", func() {
+			var lines []string
+			for _, line := range strings.Split(fail.GetFullDetailsWithLocations(err), "\n") {
+				if !strings.HasPrefix(line, "at ") {
+					lines = append(lines, line)
+				}
+			}
+			So(strings.Join(lines, "\n"), ShouldEqual, fail.GetFullDetails(err))
+		})
+	})
+
+	Convey("AreErrorsOfEqualType() for wrapped errors", t, func() {
+		Convey("should compare types of original errors", func() {
+			So(fail.AreErrorsOfEqualType(fail.New(&MyError{}), &MyError{}), ShouldBeTrue)
+			So(fail.AreErrorsOfEqualType(MyError{}, fail.New(fail.New(&MyError{}))), ShouldBeTrue)
+			So(fail.AreErrorsOfEqualType(fail.New(&MyError{}), fail.News("test")), ShouldBeFalse)
+		})
+
+		Convey("GetErrorByType() should find wrapped error", func() {
+			myErr := &MyError{msg: "wrapped"}
+			err := fail.NewErrWithReason("test", fail.New(myErr))
+			So(fail.GetErrorByType(err, &MyError{}), ShouldEqual, myErr)
+			So(fail.GetErrorByType(err, &MyError{}).(*MyError).msg, ShouldEqual, "wrapped")
+		})
+	})
+
+	Convey("Depth()", t, func() {
+		leaf := errors.New("not found")
+
+		Convey("should count levels of inner errors chain", func() {
+			So(fail.Depth(nil), ShouldEqual, 0)
+			So(fail.Depth(leaf), ShouldEqual, 1)
+			So(fail.Depth(fail.New(leaf)), ShouldEqual, 1)
+			So(fail.Depth(fail.NewErrWithReason("cannot load user", leaf)), ShouldEqual, 2)
+			So(fail.Depth(fail.NewErrWithReason("request failed", fail.NewErrWithReason("cannot load user", leaf))), ShouldEqual, 3)
+		})
+
+		Convey("should count distinct errors of cyclic chain", func() {
+			err1 := &MyCyclicError{}
+			err2 := &MyCyclicError{inner: err1}
+			err1.inner = err2
+			So(fail.Depth(err1), ShouldEqual, 2)
+		})
+	})
+	Convey("StackTraceN()", t, func() {
+		Convey("should return at most the requested number of frames", func() {
+			stackTrace := fail.StackTraceN(0, 2)
+			So(strings.Split(stackTrace, "\n"), ShouldHaveLength, 2)
+			So(strings.Split(stackTrace, "\n")[0], ShouldContainSubstring, "fail_test.go")
+			So(strings.Split(fail.StackTraceN(0, 1), "\n"), ShouldHaveLength, 1)
+		})
+
+		Convey("should skip closest callers", func() {
+			line := fail.GetStackFrames(fail.News("next line"))[0].Line + 1
+			stackTrace := func() string { return fail.StackTraceN(1, 1) }()
+			So(stackTrace, ShouldContainSubstring, fmt.Sprintf("fail_test.go:%d ", line))
+		})
+
+		Convey("should return the whole stack trace if there is no limit", func() {
+			So(strings.Split(fail.StackTraceN(0, 0), "\n"), ShouldHaveLength, len(strings.Split(fail.StackTrace(), "\n")))
+		})
+	})
+
+	Convey("Mask()", t, func() {
+		innerErr := fail.NewWithCode(&MyError{"connection refused", nil}, "DB_DOWN")
+
+		Convey("should replace message keeping masked error as inner", func() {
+			line := fail.GetStackFrames(fail.News("next line"))[0].Line + 1
+			err := fail.Mask(innerErr, "service is temporarily unavailable")
+			So(err.Error(), ShouldEqual, "service is temporarily unavailable")
+			So(fail.GetInner(err), ShouldEqual, innerErr)
+			So(fail.Is(err, innerErr), ShouldBeTrue)
+			var myErr *MyError
+			So(fail.As(err, &myErr), ShouldBeTrue)
+			So(fail.GetLocation(err), ShouldContainSubstring, fmt.Sprintf("fail_test.go:%d ", line))
+			So(fail.GetFullDetails(err), ShouldContainSubstring, "connection refused")
+		})
+
+		Convey("should not leak masked error even if IncludeInnerInError is set", func() {
+			fail.IncludeInnerInError = true
+			defer func() { fail.IncludeInnerInError = false }()
+			err := fail.Mask(innerErr, "service is temporarily unavailable")
+			So(err.Error(), ShouldEqual, "service is temporarily unavailable")
+			So(fail.NewWithInner(errors.New("request failed"), err).Error(), ShouldEqual, "request failed: service is temporarily unavailable")
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(fail.Mask(nil, "test"), ShouldBeNil)
+		})
+	})
+
+	Convey("Walk()", t, func() {
+		innerErr := errors.New("inner")
+		originalErr := &MyError{"original", nil}
+		middleErr := fail.NewWithInner(originalErr, innerErr)
+		err := fail.New(middleErr)
+
+		Convey("should visit errors depth-first with original error before inner one", func() {
+			var visited []error
+			fail.Walk(err, func(currErr error) bool {
+				visited = append(visited, currErr)
+				return true
+			})
+			So(visited, ShouldResemble, []error{err, middleErr, originalErr, innerErr})
+		})
+
+		Convey("should stop when visit returns false", func() {
+			var visited []error
+			fail.Walk(err, func(currErr error) bool {
+				visited = append(visited, currErr)
+				return currErr != originalErr
+			})
+			So(visited, ShouldResemble, []error{err, middleErr, originalErr})
+		})
+
+		Convey("should not visit anything for nil error", func() {
+			fail.Walk(nil, func(error) bool {
+				panic("should not be called")
+			})
+		})
+	})
+
+	Convey("ErrWithReason with nil reason", t, func() {
+		Convey("should render just the message", func() {
+			So(fail.ErrWithReason{Message: "loading user"}.Error(), ShouldEqual, "loading user")
+			So(fail.NewErrWithReason("loading user", nil).Error(), ShouldEqual, "loading user")
+		})
+
+		Convey("should have no inner error", func() {
+			err := fail.NewErrWithReason("loading user", nil)
+			So(fail.GetInner(err), ShouldBeNil)
+			So(fail.GetInner(fail.GetOriginalError(err)), ShouldBeNil)
+			So(fail.Cause(err), ShouldResemble, fail.ErrWithReason{Message: "loading user"})
+		})
+
+		Convey("should still capture location and stack trace", func() {
+			line := fail.GetStackFrames(fail.News("next line"))[0].Line + 1
+			err := fail.NewErrWithReason("loading user", nil)
+			So(fail.GetLocation(err), ShouldContainSubstring, fmt.Sprintf("fail_test.go:%d ", line))
+			So(fail.GetStackTrace(err), ShouldNotBeEmpty)
+		})
+	})
+
+	Convey("NearestWithStack()", t, func() {
+		Convey("should skip plain wrappers", func() {
+			extErr := fail.News("not found")
+			err := fmt.Errorf("loading user: %w", fmt.Errorf("querying: %w", extErr))
+			nearest, found := fail.NearestWithStack(err)
+			So(found, ShouldBeTrue)
+			So(nearest, ShouldEqual, extErr)
+		})
+
+		Convey("should return the outermost error with stack trace", func() {
+			err := fail.Wrap(fail.News("not found"), "loading user")
+			nearest, found := fail.NearestWithStack(err)
+			So(found, ShouldBeTrue)
+			So(nearest, ShouldEqual, err)
+		})
+
+		Convey("should skip errors with empty stack trace", func() {
+			extErr := fail.News("not found")
+			nearest, found := fail.NearestWithStack(fail.NewLocationOnly(extErr))
+			So(found, ShouldBeTrue)
+			So(nearest, ShouldEqual, extErr)
+		})
+
+		Convey("should return false if there is no error with stack trace", func() {
+			_, found := fail.NearestWithStack(fmt.Errorf("loading user: %w", errors.New("not found")))
+			So(found, ShouldBeFalse)
+			_, found = fail.NearestWithStack(nil)
+			So(found, ShouldBeFalse)
+		})
+	})
+
+	Convey("StripForTest()", t, func() {
+		build := func(id int) error {
+			return fail.Wrap(fail.NewWithInner(&MyError{"not found", nil}, fmt.Errorf("id %d: %w", id, errNotFound)), "loading user")
+		}
+
+		Convey("should render types and messages of the whole chain", func() {
+			So(fail.StripForTest(build(1)), ShouldEqual, strings.Join([]string{
+				"fail.ErrWithReason: loading user: MyError: not found. Reason: <nil>",
+				"*fail_test.MyError: MyError: not found. Reason: <nil>",
+				"*fmt.wrapError: id 1: not found",
+				"*fail.sentinelError: not found",
+			}, "\n"))
+		})
+
+		Convey("should be stable for errors built the same way from different lines", func() {
+			err1 := fail.Wrap(fail.NewWithInner(&MyError{"not found", nil}, fmt.Errorf("id %d: %w", 1, errNotFound)), "loading user")
+			So(fail.StripForTest(err1), ShouldEqual, fail.StripForTest(build(1)))
+			So(fail.StripForTest(err1), ShouldNotContainSubstring, "fail_test.go")
+		})
+
+		Convey("should return empty string for nil error", func() {
+			So(fail.StripForTest(nil), ShouldBeEmpty)
+		})
+	})
+
+	Convey("GetFullDetailsColored()", t, func() {
+		err := fail.NewWithInner(fail.WithField(errors.New("outer"), "userID", 42), fail.News("inner"))
+
+		Convey("should color messages and stack trace frames", func() {
+			details := fail.GetFullDetailsColored(err)
+			So(details, ShouldContainSubstring, "\x1b[31m*errors.errorString: outer\x1b[0m")
+			So(details, ShouldContainSubstring, "\x1b[31m*errors.errorString: inner\x1b[0m")
+			So(details, ShouldContainSubstring, fail.DetailsIndent+"\x1b[2m"+fail.GetStackFrames(err)[0].String())
+			So(details, ShouldContainSubstring, "fields: userID=42")
+			So(fail.GetFullDetails(err), ShouldNotContainSubstring, "\x1b[")
+		})
+
+		Convey("should be plain if colors are disabled", func() {
+			fail.ColorizeDetails = false
+			defer func() { fail.ColorizeDetails = true }()
+			So(fail.GetFullDetailsColored(err), ShouldEqual, fail.GetFullDetails(err))
+		})
+	})
+
+	Convey("Diff()", t, func() {
+		build := func(innerMsg string) error {
+			return fail.NewWithInner(fail.News("loading user"), fail.NewWithInner(&MyError{innerMsg, nil}, errNotFound))
+		}
+
+		Convey("should return empty string for equal chains", func() {
+			So(fail.Diff(build("query failed"), build("query failed")), ShouldBeEmpty)
+			So(fail.Diff(nil, nil), ShouldBeEmpty)
+		})
+
+		Convey("should describe message mismatch", func() {
+			So(fail.Diff(build("query failed"), build("timeout")), ShouldEqual,
+				`level 2: message mismatch: got "MyError: query failed. Reason: <nil>", want "MyError: timeout. Reason: <nil>"`)
+		})
+
+		Convey("should describe type mismatch", func() {
+			So(fail.Diff(fail.New(&MyError{"test", nil}), errors.New("MyError: test. Reason: <nil>")), ShouldEqual,
+				"level 1: type mismatch: got *fail_test.MyError, want *errors.errorString")
+		})
+
+		Convey("should describe depth mismatch", func() {
+			got := fail.NewWithInner(fail.News("loading user"), &MyError{"query failed", nil})
+			So(fail.Diff(got, build("query failed")), ShouldEqual, "depth mismatch: got 2 levels, want 3 levels")
+			So(fail.Diff(nil, got), ShouldEqual, "depth mismatch: got 0 levels, want 2 levels")
+		})
+	})
+
+	Convey("Annotate()", t, func() {
+		myErr := &MyError{"not found", nil}
+
+		Convey("should append note keeping type of the error", func() {
+			line := fail.GetStackFrames(fail.News("next line"))[0].Line + 1
+			err := fail.Annotate(fail.New(myErr), "loading user 42")
+			So(err.Error(), ShouldEqual, "MyError: not found. Reason: <nil> (loading user 42)")
+			So(fail.GetType(err), ShouldEqual, reflect.TypeOf(myErr))
+			So(fail.GetOriginalError(err), ShouldEqual, myErr)
+			So(fail.Is(err, myErr), ShouldBeTrue)
+			So(fail.GetLocation(err), ShouldContainSubstring, fmt.Sprintf("fail_test.go:%d ", line))
+		})
+
+		Convey("should append several notes in order", func() {
+			err := fail.Annotate(fail.Annotate(myErr, "first"), "second")
+			So(err.Error(), ShouldEqual, "MyError: not found. Reason: <nil> (first) (second)")
+			So(fail.GetType(err), ShouldEqual, reflect.TypeOf(myErr))
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(fail.Annotate(nil, "note"), ShouldBeNil)
+		})
+	})
+
+	Convey("WriteFullDetails()", t, func() {
+		err := fail.NewWithInner(fail.WithField(errors.New("outer"), "userID", 42), fail.News("inner"))
+
+		Convey("should write the same output as GetFullDetails", func() {
+			var builder strings.Builder
+			n, writeErr := fail.WriteFullDetails(&builder, err)
+			So(writeErr, ShouldBeNil)
+			So(builder.String(), ShouldEqual, fail.GetFullDetails(err))
+			So(n, ShouldEqual, builder.Len())
+		})
+
+		Convey("should stop on the first error of writer", func() {
+			writer := &failingWriter{limit: 10}
+			n, writeErr := fail.WriteFullDetails(writer, err)
+			So(writeErr, ShouldEqual, errWriterLimit)
+			So(n, ShouldBeLessThanOrEqualTo, 10)
+			So(writer.calls, ShouldEqual, writer.failedCall)
+		})
+
+		Convey("should write nothing for nil error", func() {
+			var builder strings.Builder
+			n, writeErr := fail.WriteFullDetails(&builder, nil)
+			So(n, ShouldEqual, 0)
+			So(writeErr, ShouldBeNil)
+		})
+	})
+
+	Convey("Types()", t, func() {
+		Convey("should return distinct types of the chain in order", func() {
+			err := fail.NewWithInner(&MyError{"outer", nil}, fail.Wrap(fail.NewWithInner(&MyError{"middle", nil}, errors.New("root")), "loading user"))
+			So(fail.Types(err), ShouldResemble, []reflect.Type{
+				reflect.TypeOf(&MyError{}),
+				reflect.TypeOf(fail.ErrWithReason{}),
+				reflect.TypeOf(errors.New("")),
+			})
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(fail.Types(nil), ShouldBeNil)
+		})
+	})
+
+	Convey("GetMessage()", t, func() {
+		Convey("should return only own message of ErrWithReason", func() {
+			err := fail.Wrap(fail.Wrap(errors.New("not found"), "querying"), "loading user")
+			So(err.Error(), ShouldEqual, "loading user: querying: not found")
+			So(fail.GetMessage(err), ShouldEqual, "loading user")
+			So(fail.GetMessage(fail.ErrWithReason{"loading user", errors.New("not found")}), ShouldEqual, "loading user")
+			So(fail.GetMessage(&fail.ErrWithReason{"loading user", nil}), ShouldEqual, "loading user")
+		})
+
+		Convey("should not include inner error of errors created by this package", func() {
+			err := fail.NewWithInner(errors.New("outer"), errors.New("inner"))
+			fail.IncludeInnerInError = true
+			defer func() { fail.IncludeInnerInError = false }()
+			So(err.Error(), ShouldEqual, "outer: inner")
+			So(fail.GetMessage(err), ShouldEqual, "outer")
+			So(fail.GetMessage(fail.Annotate(err, "note")), ShouldEqual, "outer (note)")
+		})
+
+		Convey("should strip message of wrapped error", func() {
+			So(fail.GetMessage(fail.Errorf("loading %s: %w", "user", errors.New("not found"))), ShouldEqual, "loading user")
+			So(fail.GetMessage(fail.Errorf("loading user (%w)", errors.New("not found"))), ShouldEqual, "loading user (not found)")
+			So(fail.GetMessage(fmt.Errorf("loading user: %w", errors.New("not found"))), ShouldEqual, "loading user")
+			So(fail.GetMessage(fmt.Errorf("loading user (%w)", errors.New("not found"))), ShouldEqual, "loading user (not found)")
+			So(fail.GetMessage(errors.New("not found")), ShouldEqual, "not found")
+		})
+
+		Convey("should return empty string for nil error", func() {
+			So(fail.GetMessage(nil), ShouldBeEmpty)
+		})
+	})
+
+	Convey("GetShortLocation()", t, func() {
+		Convey("should return base name of file and line", func() {
+			line := fail.GetStackFrames(fail.News("next line"))[0].Line + 1
+			err := fail.Wrap(fmt.Errorf("querying: %w", fail.News("not found")), "loading user")
+			So(fail.GetShortLocation(err), ShouldEqual, fmt.Sprintf("fail_test.go:%d", line))
+			So(fail.GetShortLocation(fmt.Errorf("loading user: %w", err)), ShouldEqual, fmt.Sprintf("fail_test.go:%d", line))
+		})
+
+		Convey("should return empty string if there is no location", func() {
+			So(fail.GetShortLocation(errors.New("test")), ShouldBeEmpty)
+			So(fail.GetShortLocation(nil), ShouldBeEmpty)
+		})
+	})
+
+	Convey("WrapCount()", t, func() {
+		base := fail.News("not found")
+
+		Convey("should count re-wrapping", func() {
+			So(fail.WrapCount(base), ShouldEqual, 0)
+			So(fail.WrapCount(fail.Wrap(fail.Wrap(fail.Wrap(base, "querying"), "loading user"), "handling")), ShouldEqual, 3)
+			So(fail.WrapCount(fail.New(fail.New(base))), ShouldEqual, 2)
+			So(fail.WrapCount(fmt.Errorf("handling: %w", fail.Wrap(base, "querying"))), ShouldEqual, 1)
+		})
+
+		Convey("should count wrapping by Errorf and Mask", func() {
+			So(fail.WrapCount(fail.Errorf("querying: %w", base)), ShouldEqual, 1)
+			So(fail.WrapCount(fail.Mask(fail.Errorf("querying: %w", base), "user not found")), ShouldEqual, 2)
+			So(fail.WrapCount(fail.Wrap(fail.Mask(base, "user not found"), "handling")), ShouldEqual, 2)
+			So(fail.WrapCount(fail.Errorf("querying: %w", errors.New("not found"))), ShouldEqual, 0)
+		})
+
+		Convey("should not count decorations and inner errors", func() {
+			So(fail.WrapCount(fail.WithTag(fail.WithField(fail.Wrap(base, "querying"), "id", 1), "db")), ShouldEqual, 1)
+			So(fail.WrapCount(fail.NewWithInner(errors.New("outer"), fail.Wrap(base, "querying"))), ShouldEqual, 0)
+		})
+
+		Convey("should return 0 for errors not created by this package", func() {
+			So(fail.WrapCount(errors.New("test")), ShouldEqual, 0)
+			So(fail.WrapCount(nil), ShouldEqual, 0)
+		})
+	})
+
+	Convey("GetErrorByReflectType()", t, func() {
+		myErr := &MyError{"not found", nil}
+		inner := fail.New(myErr)
+		err := fail.NewWithInner(fail.News("loading user"), fail.Wrap(inner, "querying"))
+
+		Convey("should return error of the given type", func() {
+			So(fail.GetErrorByReflectType(err, reflect.TypeOf(MyError{})), ShouldEqual, myErr)
+			So(fail.GetErrorByReflectType(err, reflect.TypeOf(myErr)), ShouldEqual, myErr)
+			So(fail.GetErrorByReflectType(err, reflect.TypeOf(fail.ErrWithReason{})), ShouldResemble, fail.GetOriginalError(fail.GetInner(err)))
+			So(fail.GetErrorByReflectType(err, reflect.TypeOf(fail.ErrWithReason{})), ShouldHaveSameTypeAs, fail.ErrWithReason{})
+			So(fail.GetErrorByReflectType(err, reflect.TypeOf(myErr)), ShouldEqual, fail.GetErrorByType(err, myErr))
+		})
+
+		Convey("should return nil if there is no such error", func() {
+			So(fail.GetErrorByReflectType(err, reflect.TypeOf(MyErrWithIs{})), ShouldBeNil)
+			So(fail.GetErrorByReflectType(err, nil), ShouldBeNil)
+			So(fail.GetErrorByReflectType(nil, reflect.TypeOf(MyError{})), ShouldBeNil)
+		})
+	})
+
+}
+
+type MyErrWithIs struct {
+	code string
+}
+
+func (err MyErrWithIs) Error() string {
+	return "MyErrWithIs: " + err.code
+}
+
+func (err MyErrWithIs) Is(target error) bool {
+	switch targetErr := target.(type) {
+	case MyErrWithIs:
+		return targetErr.code == err.code
+	case *MyErrWithIs:
+		return targetErr.code == err.code
+	}
+	return false
+}
+
+
+func createErrorInHelper(inner error) error {
+	return fail.NewWithInner(fail.News("outer"), inner)
+}
+
+type MyCyclicError struct {
+	msg   string
+	inner error
+}
+
+func (err *MyCyclicError) Error() string {
+	return err.msg
+}
+
+func (err *MyCyclicError) InnerError() error {
+	return err.inner
+}
+
+var errNotFound = fail.Sentinel("not found")
+
+// myWrapper is third-party error wrapper which does not provide stack trace itself.
+type myWrapper struct {
+	original error
+}
+
+func (err myWrapper) Error() string {
+	return "wrapped: " + err.original.Error()
+}
+
+func (err myWrapper) OriginalError() error {
+	return err.original
+}
+
+func newsInHelper(text string) error {
+	return fail.News(text, 1)
+}
+
+func newfInHelper(format string, a ...interface{}) error {
+	return fail.NewfWithStackSkip(1, format, a...)
+}
+
+var errWriterLimit = errors.New("writer limit reached")
+
+// failingWriter fails when more than limit bytes are written.
+type failingWriter struct {
+	limit      int
+	written    int
+	calls      int
+	failedCall int
+}
+
+func (writer *failingWriter) Write(p []byte) (int, error) {
+	writer.calls++
+	if writer.written+len(p) > writer.limit {
+		writer.failedCall = writer.calls
+		n := writer.limit - writer.written
+		writer.written = writer.limit
+		return n, errWriterLimit
+	}
+	writer.written += len(p)
+	return len(p), nil
+}
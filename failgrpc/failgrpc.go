@@ -0,0 +1,126 @@
+// Package failgrpc provides routines to attach gRPC codes to errors and to convert errors to gRPC statuses.
+// It is a separate package to keep google.golang.org/grpc dependency optional.
+package failgrpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nbgo/fail"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ErrorWithGRPCCode is error which provides gRPC code.
+//
+// GRPCCode returns error's gRPC code.
+type ErrorWithGRPCCode interface {
+	error
+	GRPCCode() codes.Code
+}
+
+// codedError is error with gRPC code.
+// Implements ErrorWithGRPCCode and fail.ErrorWrapper, so the type of the original error is preserved.
+type codedError struct {
+	err  error
+	code codes.Code
+}
+
+func (codedErr codedError) Error() string {
+	return codedErr.err.Error()
+}
+func (codedErr codedError) GRPCCode() codes.Code {
+	return codedErr.code
+}
+func (codedErr codedError) OriginalError() error {
+	return fail.GetOriginalError(codedErr.err)
+}
+func (codedErr codedError) Unwrap() error {
+	return codedErr.err
+}
+
+// GRPCStatus allows status.FromError and status.Code to get status of the error.
+func (codedErr codedError) GRPCStatus() *status.Status {
+	return ToStatus(codedErr)
+}
+
+// NewWithCode creates a new error like fail.New does and attaches the given gRPC code to it.
+// Newly created error implements ErrorWithGRPCCode. If the given error is nil then nil is returned.
+func NewWithCode(err error, code codes.Code, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	return fail.New(codedError{err, code}, stackSkip)
+}
+
+// GetCode returns the first gRPC code found in the chain of the given error.
+// If there is no error implementing ErrorWithGRPCCode then false is returned.
+func GetCode(err error) (codes.Code, bool) {
+	var errWithCode ErrorWithGRPCCode
+	if fail.As(err, &errWithCode) {
+		return errWithCode.GRPCCode(), true
+	}
+	return codes.Unknown, false
+}
+
+// ToStatus converts the given error to gRPC status.
+// Status code is taken from the error chain (codes.Unknown is used if there is no code) and status message is error message.
+// Fields of the error chain (see fail.GetFields and fail.RedactFields) are attached to status as structpb.Struct details.
+// Nil error is converted to status with codes.OK.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+
+	code, _ := GetCode(err)
+	st := status.New(code, err.Error())
+	if fields := fail.RedactFields(fail.GetFields(err)); len(fields) > 0 {
+		details, structErr := structpb.NewStruct(toStructFields(fields))
+		if structErr == nil {
+			if stWithDetails, detailsErr := st.WithDetails(details); detailsErr == nil {
+				st = stWithDetails
+			}
+		}
+	}
+	return st
+}
+
+// FromStatus converts the given gRPC status to error with gRPC code and fields found in status details.
+// Status with codes.OK is converted to nil.
+func FromStatus(st *status.Status) error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	var fields map[string]interface{}
+	for _, detail := range st.Details() {
+		if detailStruct, isStruct := detail.(*structpb.Struct); isStruct {
+			for key, value := range detailStruct.AsMap() {
+				if fields == nil {
+					fields = make(map[string]interface{})
+				}
+				fields[key] = value
+			}
+		}
+	}
+	return fail.NewWithFields(codedError{errors.New(st.Message()), st.Code()}, fields, 1)
+}
+
+// toStructFields converts field values not supported by structpb to strings.
+func toStructFields(fields map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		if _, err := structpb.NewValue(value); err != nil {
+			value = fmt.Sprint(value)
+		}
+		result[key] = value
+	}
+	return result
+}
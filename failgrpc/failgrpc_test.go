@@ -0,0 +1,72 @@
+package failgrpc_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/nbgo/fail"
+	"github.com/nbgo/fail/failgrpc"
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFailGRPC(t *testing.T) {
+	Convey("NewWithCode() and GetCode()", t, func() {
+		originalErr := errors.New("user not found")
+		err := failgrpc.NewWithCode(originalErr, codes.NotFound)
+
+		Convey("should keep original error", func() {
+			So(err.Error(), ShouldEqual, "user not found")
+			So(fail.GetOriginalError(err), ShouldEqual, originalErr)
+			So(fail.GetType(err), ShouldEqual, reflect.TypeOf(originalErr))
+			So(fail.Is(err, originalErr), ShouldBeTrue)
+		})
+
+		Convey("should keep code after wrapping", func() {
+			code, ok := failgrpc.GetCode(fail.Wrap(err, "loading user"))
+			So(ok, ShouldBeTrue)
+			So(code, ShouldEqual, codes.NotFound)
+		})
+
+		Convey("should report absence of code", func() {
+			_, ok := failgrpc.GetCode(fail.News("test"))
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(failgrpc.NewWithCode(nil, codes.NotFound), ShouldBeNil)
+		})
+	})
+
+	Convey("ToStatus() and FromStatus()", t, func() {
+		err := fail.WithField(failgrpc.NewWithCode(errors.New("user not found"), codes.NotFound), "userID", 42)
+
+		Convey("should convert coded error to status", func() {
+			st := failgrpc.ToStatus(err)
+			So(st.Code(), ShouldEqual, codes.NotFound)
+			So(st.Message(), ShouldEqual, "user not found")
+			So(len(st.Details()), ShouldEqual, 1)
+			So(status.Code(fail.Wrap(err, "loading user")), ShouldEqual, codes.NotFound)
+		})
+
+		Convey("should convert status back to coded error", func() {
+			convertedErr := failgrpc.FromStatus(failgrpc.ToStatus(err))
+			So(convertedErr.Error(), ShouldEqual, "user not found")
+			code, ok := failgrpc.GetCode(convertedErr)
+			So(ok, ShouldBeTrue)
+			So(code, ShouldEqual, codes.NotFound)
+			So(fail.GetFields(convertedErr), ShouldResemble, map[string]interface{}{"userID": float64(42)})
+		})
+
+		Convey("should use unknown code for error without code", func() {
+			So(failgrpc.ToStatus(errors.New("test")).Code(), ShouldEqual, codes.Unknown)
+		})
+
+		Convey("should convert nil error to OK status and back", func() {
+			So(failgrpc.ToStatus(nil).Code(), ShouldEqual, codes.OK)
+			So(failgrpc.FromStatus(failgrpc.ToStatus(nil)), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,45 @@
+// Package faillogrus provides routines to log errors with github.com/sirupsen/logrus including their additional information.
+// It is a separate package to keep github.com/sirupsen/logrus dependency optional.
+package faillogrus
+
+import (
+	"github.com/nbgo/fail"
+	"github.com/sirupsen/logrus"
+)
+
+// Keys of fields with additional information of errors.
+const (
+	LocationKey   = "location"
+	CodeKey       = "code"
+	StackTraceKey = "stackTrace"
+)
+
+// Fields returns logrus fields with the given error (under logrus.ErrorKey), its fields (see fail.GetFields and fail.RedactFields)
+// and its location, code and stack trace (if error has them).
+// For plain error the result contains the error only.
+func Fields(err error) logrus.Fields {
+	if err == nil {
+		return logrus.Fields{}
+	}
+
+	result := logrus.Fields{}
+	for key, value := range fail.RedactFields(fail.GetFields(err)) {
+		result[key] = value
+	}
+	if location := fail.GetLocation(err); location != "" {
+		result[LocationKey] = location
+	}
+	if code := fail.GetCode(err); code != "" {
+		result[CodeKey] = code
+	}
+	if stackTrace := fail.GetStackTrace(err); stackTrace != "" {
+		result[StackTraceKey] = stackTrace
+	}
+	result[logrus.ErrorKey] = err
+	return result
+}
+
+// WithError works like entry.WithError but also adds all fields returned by Fields.
+func WithError(entry *logrus.Entry, err error) *logrus.Entry {
+	return entry.WithFields(Fields(err))
+}
@@ -0,0 +1,54 @@
+package faillogrus_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/nbgo/fail"
+	"github.com/nbgo/fail/faillogrus"
+	"github.com/sirupsen/logrus"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFailLogrus(t *testing.T) {
+	Convey("Fields()", t, func() {
+		Convey("should include fields of wrapped error", func() {
+			inner := fail.NewWithCode(fail.WithField(errors.New("not found"), "userID", 42), "NOT_FOUND")
+			err := fail.Wrap(inner, "loading user")
+			fields := faillogrus.Fields(err)
+			So(fields[logrus.ErrorKey], ShouldEqual, err)
+			So(fields["userID"], ShouldEqual, 42)
+			So(fields[faillogrus.CodeKey], ShouldEqual, "NOT_FOUND")
+			So(fields[faillogrus.LocationKey], ShouldEqual, fail.GetLocation(err))
+			So(fields[faillogrus.StackTraceKey], ShouldEqual, fail.GetStackTrace(err))
+		})
+
+		Convey("should redact values of registered keys", func() {
+			fail.RegisterRedactedFieldKey("logrusToken")
+			fields := faillogrus.Fields(fail.WithField(errors.New("unauthorized"), "logrusToken", "secret"))
+			So(fields["logrusToken"], ShouldEqual, fail.RedactedValue)
+		})
+
+		Convey("should degrade to the error only for plain error", func() {
+			err := errors.New("plain")
+			So(faillogrus.Fields(err), ShouldResemble, logrus.Fields{logrus.ErrorKey: err})
+			So(faillogrus.Fields(nil), ShouldBeEmpty)
+		})
+	})
+
+	Convey("WithError()", t, func() {
+		logger := logrus.New()
+		logger.Out = io.Discard
+		entry := logrus.NewEntry(logger).WithField("request", "r1")
+		err := fail.WithField(errors.New("not found"), "userID", 42)
+
+		Convey("should add all fields of the error to entry", func() {
+			result := faillogrus.WithError(entry, err)
+			So(result.Data["request"], ShouldEqual, "r1")
+			So(result.Data["userID"], ShouldEqual, 42)
+			So(result.Data[logrus.ErrorKey], ShouldEqual, err)
+			So(result.Data[faillogrus.LocationKey], ShouldContainSubstring, "faillogrus_test.go")
+		})
+	})
+}
@@ -0,0 +1,74 @@
+// Package failtest provides helpers to assert properties of errors in tests using standard testing package.
+// Failure messages include full details of the asserted error (see fail.GetFullDetails).
+package failtest
+
+import (
+	"reflect"
+
+	"github.com/nbgo/fail"
+)
+
+// TestingT is the subset of testing.TB used by assertions.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertIs checks that target is found in the chain of the given error (see fail.Is).
+// It returns true if the assertion has passed.
+func AssertIs(t TestingT, err, target error) bool {
+	t.Helper()
+	if fail.Is(err, target) {
+		return true
+	}
+	t.Errorf("expected error chain to contain %q, but it does not.\nError details:\n%v", target, details(err))
+	return false
+}
+
+// AssertCode checks that the given error has the given code (see fail.GetCode).
+// It returns true if the assertion has passed.
+func AssertCode(t TestingT, err error, code string) bool {
+	t.Helper()
+	actualCode := fail.GetCode(err)
+	if actualCode == code {
+		return true
+	}
+	t.Errorf("expected error code %q, but got %q.\nError details:\n%v", code, actualCode, details(err))
+	return false
+}
+
+// AssertHasField checks that the given error has field with the given key and value (see fail.GetFields).
+// Values are compared by reflect.DeepEqual.
+// It returns true if the assertion has passed.
+func AssertHasField(t TestingT, err error, key string, value interface{}) bool {
+	t.Helper()
+	actualValue, hasField := fail.GetFields(err)[key]
+	if !hasField {
+		t.Errorf("expected error to have field %q, but it does not.\nError details:\n%v", key, details(err))
+		return false
+	}
+	if !reflect.DeepEqual(actualValue, value) {
+		t.Errorf("expected error field %q to be %#v, but got %#v.\nError details:\n%v", key, value, actualValue, details(err))
+		return false
+	}
+	return true
+}
+
+// AssertChainEqual checks that chains of inner errors of the given errors are structurally equal (see fail.ChainEqual).
+// Failure message describes where the chains differ (see fail.Diff).
+// It returns true if the assertion has passed.
+func AssertChainEqual(t TestingT, got, want error) bool {
+	t.Helper()
+	if fail.ChainEqual(got, want) {
+		return true
+	}
+	t.Errorf("expected error chains to be equal, but they differ:\n%v\nError details:\n%v", fail.Diff(got, want), details(got))
+	return false
+}
+
+func details(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return fail.GetFullDetails(err)
+}
@@ -0,0 +1,104 @@
+package failtest_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nbgo/fail"
+	"github.com/nbgo/fail/failtest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeT captures failures reported by assertions.
+type fakeT struct {
+	failures []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func TestFailTest(t *testing.T) {
+	var _ failtest.TestingT = t
+
+	sentinel := fail.Sentinel("not found")
+	err := fail.Wrap(fail.NewWithCode(fail.WithField(sentinel, "id", 42), "NOT_FOUND"), "loading user")
+
+	Convey("AssertIs()", t, func() {
+		fake := &fakeT{}
+
+		Convey("should pass if target is in the chain", func() {
+			So(failtest.AssertIs(fake, err, sentinel), ShouldBeTrue)
+			So(fake.failures, ShouldBeEmpty)
+		})
+
+		Convey("should fail with error details otherwise", func() {
+			So(failtest.AssertIs(fake, err, errors.New("other")), ShouldBeFalse)
+			So(fake.failures, ShouldHaveLength, 1)
+			So(fake.failures[0], ShouldContainSubstring, `expected error chain to contain "other"`)
+			So(fake.failures[0], ShouldContainSubstring, fail.GetFullDetails(err))
+		})
+	})
+
+	Convey("AssertCode()", t, func() {
+		fake := &fakeT{}
+
+		Convey("should pass if code matches", func() {
+			So(failtest.AssertCode(fake, err, "NOT_FOUND"), ShouldBeTrue)
+			So(fake.failures, ShouldBeEmpty)
+		})
+
+		Convey("should fail with error details otherwise", func() {
+			So(failtest.AssertCode(fake, err, "INTERNAL"), ShouldBeFalse)
+			So(fake.failures, ShouldHaveLength, 1)
+			So(fake.failures[0], ShouldContainSubstring, `expected error code "INTERNAL", but got "NOT_FOUND"`)
+			So(fake.failures[0], ShouldContainSubstring, fail.GetFullDetails(err))
+		})
+	})
+
+	Convey("AssertHasField()", t, func() {
+		fake := &fakeT{}
+
+		Convey("should pass if field matches", func() {
+			So(failtest.AssertHasField(fake, err, "id", 42), ShouldBeTrue)
+			So(fake.failures, ShouldBeEmpty)
+		})
+
+		Convey("should fail if field is missing", func() {
+			So(failtest.AssertHasField(fake, err, "name", "bob"), ShouldBeFalse)
+			So(fake.failures, ShouldHaveLength, 1)
+			So(fake.failures[0], ShouldContainSubstring, `expected error to have field "name"`)
+		})
+
+		Convey("should fail if field value differs", func() {
+			So(failtest.AssertHasField(fake, err, "id", 43), ShouldBeFalse)
+			So(fake.failures, ShouldHaveLength, 1)
+			So(fake.failures[0], ShouldContainSubstring, `expected error field "id" to be 43, but got 42`)
+		})
+
+		Convey("should fail for nil error", func() {
+			So(failtest.AssertHasField(fake, nil, "id", 42), ShouldBeFalse)
+			So(fake.failures[0], ShouldContainSubstring, "<nil>")
+		})
+	})
+	Convey("AssertChainEqual()", t, func() {
+		fake := &fakeT{}
+
+		Convey("should pass if chains are equal", func() {
+			So(failtest.AssertChainEqual(fake, fail.Wrap(sentinel, "loading user"), fail.Wrap(sentinel, "loading user")), ShouldBeTrue)
+			So(fake.failures, ShouldBeEmpty)
+		})
+
+		Convey("should fail with diff otherwise", func() {
+			got := fail.NewWithInner(fail.News("loading user"), errors.New("timeout"))
+			want := fail.NewWithInner(fail.News("loading user"), errors.New("not found"))
+			So(failtest.AssertChainEqual(fake, got, want), ShouldBeFalse)
+			So(fake.failures, ShouldHaveLength, 1)
+			So(fake.failures[0], ShouldContainSubstring, fail.Diff(got, want))
+			So(fake.failures[0], ShouldContainSubstring, `level 2: message mismatch: got "timeout", want "not found"`)
+		})
+	})
+}
@@ -0,0 +1,62 @@
+// Package failzap provides routines to log errors with go.uber.org/zap including their additional information.
+// It is a separate package to keep go.uber.org/zap dependency optional.
+package failzap
+
+import (
+	"github.com/nbgo/fail"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Keys of fields with additional information of errors.
+const (
+	LocationKey   = "location"
+	CodeKey       = "code"
+	StackTraceKey = "stackTrace"
+	MessageKey    = "message"
+)
+
+// Fields returns zap fields built from fields of the given error (see fail.GetFields and fail.RedactFields)
+// plus separate fields for location, code and stack trace (if error has them).
+func Fields(err error) []zap.Field {
+	if err == nil {
+		return nil
+	}
+
+	errFields := fail.RedactFields(fail.GetFields(err))
+	result := make([]zap.Field, 0, len(errFields)+3)
+	for key, value := range errFields {
+		result = append(result, zap.Any(key, value))
+	}
+	if location := fail.GetLocation(err); location != "" {
+		result = append(result, zap.String(LocationKey, location))
+	}
+	if code := fail.GetCode(err); code != "" {
+		result = append(result, zap.String(CodeKey, code))
+	}
+	if stackTrace := fail.GetStackTrace(err); stackTrace != "" {
+		result = append(result, zap.String(StackTraceKey, stackTrace))
+	}
+	return result
+}
+
+// Error returns zap field with key "error" which bundles error message and all fields returned by Fields.
+func Error(err error) zap.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.Object("error", errorObject{err})
+}
+
+// errorObject implements zapcore.ObjectMarshaler for error.
+type errorObject struct {
+	err error
+}
+
+func (errObj errorObject) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddString(MessageKey, errObj.err.Error())
+	for _, field := range Fields(errObj.err) {
+		field.AddTo(encoder)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package failzap_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	"github.com/nbgo/fail/failzap"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFailZap(t *testing.T) {
+	Convey("Fields()", t, func() {
+		err := fail.Wrap(fail.NewWithCode(fail.WithField(errors.New("not found"), "userID", 42), "NOT_FOUND"), "loading user")
+
+		Convey("should include attached keys, location, code and stack trace", func() {
+			fields := fieldsToMap(failzap.Fields(err))
+			So(fields["userID"], ShouldEqual, 42)
+			So(fields[failzap.CodeKey], ShouldEqual, "NOT_FOUND")
+			So(fields[failzap.LocationKey], ShouldEqual, fail.GetLocation(err))
+			So(fields[failzap.StackTraceKey], ShouldEqual, fail.GetStackTrace(err))
+		})
+
+		Convey("should redact values of registered keys", func() {
+			fail.RegisterRedactedFieldKey("zapToken")
+			fields := fieldsToMap(failzap.Fields(fail.WithField(err, "zapToken", "secret")))
+			So(fields["zapToken"], ShouldEqual, fail.RedactedValue)
+		})
+
+		Convey("should return only attached keys for plain error", func() {
+			So(failzap.Fields(errors.New("plain")), ShouldBeEmpty)
+			So(failzap.Fields(nil), ShouldBeNil)
+		})
+	})
+
+	Convey("Error()", t, func() {
+		err := fail.WithField(errors.New("not found"), "userID", 42)
+
+		Convey("should bundle message and fields", func() {
+			encoder := zapcore.NewMapObjectEncoder()
+			failzap.Error(err).AddTo(encoder)
+			errorFields := encoder.Fields["error"].(map[string]interface{})
+			So(errorFields[failzap.MessageKey], ShouldEqual, "not found")
+			So(errorFields["userID"], ShouldEqual, 42)
+			So(errorFields[failzap.LocationKey], ShouldContainSubstring, "failzap_test.go")
+		})
+
+		Convey("should be skipped for nil error", func() {
+			So(failzap.Error(nil), ShouldResemble, zap.Skip())
+		})
+	})
+}
+
+func fieldsToMap(fields []zap.Field) map[string]interface{} {
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+	return encoder.Fields
+}
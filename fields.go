@@ -0,0 +1,93 @@
+package fail
+
+import "fmt"
+
+// FieldCollisionPolicy defines how GetAllFields resolves fields with the same key found on different levels of the chain.
+type FieldCollisionPolicy int
+
+// Supported field collision policies.
+const (
+	// FieldCollisionPrefixLevel keeps the value of the outer error under the original key and puts values of deeper
+	// errors under the key prefixed with their level, e.g. "1.id".
+	FieldCollisionPrefixLevel FieldCollisionPolicy = iota
+	// FieldCollisionOuterWins keeps only the value of the outermost error.
+	FieldCollisionOuterWins
+)
+
+// AllFieldsCollisionPolicy is the policy used by GetAllFields to resolve fields with the same key.
+var AllFieldsCollisionPolicy = FieldCollisionPrefixLevel
+
+// GetAllFields returns fields of all errors in the chain of the given error merged into a single map
+// (e.g. to build a single structured log record).
+// The given error and its original errors (see ErrorWrapper) are on level 0, their inner error (see CompositeError)
+// is on level 1 and so on. Errors wrapped only via Unwrap are on the next level as well.
+// Fields with the same key on the same level are resolved like in GetFields, on different levels
+// they are resolved according to AllFieldsCollisionPolicy.
+// If there are no fields nil is returned.
+func GetAllFields(err error) map[string]interface{} {
+	var result map[string]interface{}
+	policy := AllFieldsCollisionPolicy
+	walkLevels(err, 0, visitedErrors{}, func(currErr error, level int) {
+		for key, value := range ownFields(currErr) {
+			if result == nil {
+				result = make(map[string]interface{})
+			}
+			if _, exists := result[key]; !exists {
+				result[key] = value
+				continue
+			}
+			if level == 0 || policy != FieldCollisionPrefixLevel {
+				continue
+			}
+			prefixedKey := fmt.Sprintf("%d.%s", level, key)
+			if _, exists := result[prefixedKey]; !exists {
+				result[prefixedKey] = value
+			}
+		}
+	})
+	return result
+}
+
+// ownFields returns fields attached to the given error itself, without fields of the errors it wraps.
+func ownFields(err error) map[string]interface{} {
+	if extErr, isExtErr := err.(*extendedError); isExtErr {
+		return extErr.fields
+	}
+	if errWithFields, isErrWithFields := err.(ErrorWithFields); isErrWithFields {
+		return errWithFields.Fields()
+	}
+	return nil
+}
+
+// walkLevels calls visit for the given error and, depth-first, for all errors it wraps passing the level of each error.
+func walkLevels(err error, level int, visited visitedErrors, visit func(error, int)) {
+	if err == nil || !visited.visit(err) {
+		return
+	}
+	visit(err, level)
+
+	if extErr, isExtErr := err.(*extendedError); isExtErr {
+		if extErr.sealed {
+			return
+		}
+		// walk the direct original error as OriginalError skips intermediate wrappers
+		walkLevels(extErr.originalError, level, visited, visit)
+		walkLevels(extErr.innerError, level+1, visited, visit)
+		return
+	}
+
+	errorWrapper, isErrorWrapper := err.(ErrorWrapper)
+	compositeError, isCompositeError := err.(CompositeError)
+	if !isErrorWrapper && !isCompositeError {
+		for _, wrappedErr := range unwrap(err) {
+			walkLevels(wrappedErr, level+1, visited, visit)
+		}
+		return
+	}
+	if isErrorWrapper {
+		walkLevels(errorWrapper.OriginalError(), level, visited, visit)
+	}
+	if isCompositeError {
+		walkLevels(compositeError.InnerError(), level+1, visited, visit)
+	}
+}
@@ -0,0 +1,41 @@
+package fail_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetAllFields(t *testing.T) {
+	Convey("GetAllFields()", t, func() {
+		inner := fail.NewWithFields(errors.New("not found"), map[string]interface{}{"id": 1, "table": "users"})
+		err := fail.NewWithInner(fail.WithField(errors.New("cannot load user"), "id", 2), inner)
+
+		Convey("should prefix keys of deeper level on collision", func() {
+			So(fail.GetAllFields(err), ShouldResemble, map[string]interface{}{"id": 2, "1.id": 1, "table": "users"})
+		})
+
+		Convey("should keep value of outer error on collision if configured", func() {
+			defer func(policy fail.FieldCollisionPolicy) { fail.AllFieldsCollisionPolicy = policy }(fail.AllFieldsCollisionPolicy)
+			fail.AllFieldsCollisionPolicy = fail.FieldCollisionOuterWins
+			So(fail.GetAllFields(err), ShouldResemble, map[string]interface{}{"id": 2, "table": "users"})
+		})
+
+		Convey("should treat wrapper and its original error as the same level", func() {
+			err := fail.WithField(fail.WithField(errors.New("failed"), "id", 1), "id", 2)
+			So(fail.GetAllFields(err), ShouldResemble, map[string]interface{}{"id": 2})
+		})
+
+		Convey("should include fields of errors wrapped by Wrap", func() {
+			err := fail.Wrap(inner, "loading user")
+			So(fail.GetAllFields(err), ShouldResemble, map[string]interface{}{"id": 1, "table": "users"})
+		})
+
+		Convey("should return nil if there are no fields", func() {
+			So(fail.GetAllFields(errors.New("plain")), ShouldBeNil)
+			So(fail.GetAllFields(nil), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,37 @@
+package fail_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFind(t *testing.T) {
+	Convey("Find()", t, func() {
+		coded := fail.NewWithCode(errors.New("user 42 not found"), "NOT_FOUND")
+		err := fail.Wrap(fail.NewWithInner(fail.News("cannot load user"), coded), "request failed")
+
+		Convey("should find error by code", func() {
+			found := fail.Find(err, func(currErr error) bool {
+				errWithCode, isErrWithCode := currErr.(fail.ErrorWithCode)
+				return isErrWithCode && errWithCode.Code() == "NOT_FOUND"
+			})
+			So(found, ShouldEqual, coded)
+		})
+
+		Convey("should find error by regexp on message", func() {
+			pattern := regexp.MustCompile(`^user \d+ not found$`)
+			found := fail.Find(err, func(currErr error) bool { return pattern.MatchString(currErr.Error()) })
+			So(found, ShouldNotBeNil)
+			So(found.Error(), ShouldEqual, "user 42 not found")
+		})
+
+		Convey("should return nil if nothing matches", func() {
+			So(fail.Find(err, func(error) bool { return false }), ShouldBeNil)
+			So(fail.Find(nil, func(error) bool { return true }), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,37 @@
+package fail
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+)
+
+// FingerprintIncludesLine defines if the line of the place where the error occurred is included in the fingerprint
+// computed by Fingerprint. It is off by default, so fingerprints survive edits of the file which shift lines,
+// but then errors occurred in different lines of the same function share a fingerprint.
+var FingerprintIncludesLine = false
+
+// Fingerprint returns stable hash of the given error intended for grouping errors in error-tracking systems.
+// It is computed from the types of all errors in the chain of the given error (the chain is walked the same way as in Is)
+// and the file and function of the place where the nearest error created by this package occurred
+// (and its line if FingerprintIncludesLine is set). Messages, fields and other dynamic content are ignored,
+// so errors created by the same code path share a fingerprint.
+// If the given error is nil then empty string is returned.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	hash := fnv.New64a()
+	walk(err, func(currErr error) bool {
+		fmt.Fprintf(hash, "%v\n", reflect.TypeOf(currErr))
+		return true
+	})
+	if frame, found := GetLocationFrame(err); found {
+		fmt.Fprintf(hash, "%s:%s", frame.File, frame.Function)
+		if FingerprintIncludesLine {
+			fmt.Fprintf(hash, ":%d", frame.Line)
+		}
+	}
+	return fmt.Sprintf("%016x", hash.Sum64())
+}
@@ -0,0 +1,51 @@
+package fail_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFingerprint(t *testing.T) {
+	Convey("Fingerprint()", t, func() {
+		loadUser := func(id int) error {
+			return fail.Wrap(fail.New(fmt.Errorf("user %d not found", id)), "loading user")
+		}
+
+		Convey("should be equal for errors from the same code path", func() {
+			So(fail.Fingerprint(loadUser(1)), ShouldEqual, fail.Fingerprint(loadUser(2)))
+			So(fail.Fingerprint(loadUser(1)), ShouldHaveLength, 16)
+		})
+
+		Convey("should differ for errors from different code paths", func() {
+			err := fail.Wrap(fail.New(fmt.Errorf("user %d not found", 1)), "loading user")
+			So(fail.Fingerprint(err), ShouldNotEqual, fail.Fingerprint(loadUser(1)))
+		})
+
+		Convey("should differ for errors of different types", func() {
+			create := func(err error) error { return fail.New(err) }
+			So(fail.Fingerprint(create(errors.New("failed"))), ShouldNotEqual, fail.Fingerprint(create(&MyError{"failed", nil})))
+		})
+
+		Convey("should consider line only if FingerprintIncludesLine is set", func() {
+			create := func(first bool) error {
+				if first {
+					return fail.News("failed")
+				}
+				return fail.News("failed")
+			}
+			So(fail.Fingerprint(create(true)), ShouldEqual, fail.Fingerprint(create(false)))
+
+			fail.FingerprintIncludesLine = true
+			defer func() { fail.FingerprintIncludesLine = false }()
+			So(fail.Fingerprint(create(true)), ShouldNotEqual, fail.Fingerprint(create(false)))
+		})
+
+		Convey("should be empty for nil error", func() {
+			So(fail.Fingerprint(nil), ShouldBeEmpty)
+		})
+	})
+}
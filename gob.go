@@ -0,0 +1,40 @@
+package fail
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+func init() {
+	gob.Register(&TransportError{})
+}
+
+// ToTransportError converts the given error to *TransportError which can be sent by encoding/gob
+// (it is registered by gob.Register, so it can be sent as a value of error interface as well).
+// Values of fields have to be of types supported by gob (types used via interfaces have to be registered by gob.Register).
+// If the given error is nil then nil is returned.
+func ToTransportError(err error) *TransportError {
+	if err == nil {
+		return nil
+	}
+	return Decode(Encode(err)).(*TransportError)
+}
+
+// GobEncode implements gob.GobEncoder.
+func (err *TransportError) GobEncode() ([]byte, error) {
+	var result bytes.Buffer
+	if encodeErr := gob.NewEncoder(&result).Encode(err.dto); encodeErr != nil {
+		return nil, encodeErr
+	}
+	return result.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder.
+func (err *TransportError) GobDecode(data []byte) error {
+	var dto ErrorDTO
+	if decodeErr := gob.NewDecoder(bytes.NewReader(data)).Decode(&dto); decodeErr != nil {
+		return decodeErr
+	}
+	*err = *Decode(&dto).(*TransportError)
+	return nil
+}
@@ -0,0 +1,49 @@
+package fail_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGob(t *testing.T) {
+	Convey("TransportError", t, func() {
+		inner := fail.NewWithCode(fail.WithField(errors.New("not found"), "id", 42), "NOT_FOUND")
+		err := fail.NewWithInner(fail.News("cannot load user"), inner)
+
+		Convey("should keep message and stack trace after gob round trip", func() {
+			var buffer bytes.Buffer
+			So(gob.NewEncoder(&buffer).Encode(fail.ToTransportError(err)), ShouldBeNil)
+			var decoded fail.TransportError
+			So(gob.NewDecoder(&buffer).Decode(&decoded), ShouldBeNil)
+
+			So(decoded.Error(), ShouldEqual, "cannot load user")
+			So(fail.GetStackTrace(&decoded), ShouldEqual, fail.GetStackTrace(err))
+			So(fail.GetLocation(&decoded), ShouldEqual, fail.GetLocation(err))
+			So(fail.GetInner(&decoded).Error(), ShouldEqual, "not found")
+			So(fail.GetStackTrace(fail.GetInner(&decoded)), ShouldEqual, fail.GetStackTrace(inner))
+			So(fail.GetCode(&decoded), ShouldEqual, "NOT_FOUND")
+			So(fail.GetFields(&decoded), ShouldResemble, map[string]interface{}{"id": 42})
+		})
+
+		Convey("should be sent as error interface value", func() {
+			type reply struct {
+				Err error
+			}
+			var buffer bytes.Buffer
+			So(gob.NewEncoder(&buffer).Encode(reply{fail.ToTransportError(err)}), ShouldBeNil)
+			var decoded reply
+			So(gob.NewDecoder(&buffer).Decode(&decoded), ShouldBeNil)
+			So(decoded.Err.Error(), ShouldEqual, "cannot load user")
+			So(fail.GetStackTrace(decoded.Err), ShouldEqual, fail.GetStackTrace(err))
+		})
+
+		Convey("ToTransportError() should return nil for nil error", func() {
+			So(fail.ToTransportError(nil), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,56 @@
+package fail
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// CaptureGoroutineID enables capturing of the id of the goroutine which creates an error.
+// Captured id is available via GetGoroutineID and is written by GetFullDetails as "goroutine N:" header line.
+// It is disabled by default as it adds overhead to error creation.
+var CaptureGoroutineID = false
+
+// GetGoroutineID returns the id of the goroutine which created the first error with captured goroutine id
+// found in the chain of the given error (the chain is walked the same way as in Is).
+// If there is no such error then false is returned.
+func GetGoroutineID(err error) (int, bool) {
+	var result int
+	walk(err, func(currErr error) bool {
+		result = ownGoroutineID(currErr)
+		return result == 0
+	})
+	return result, result != 0
+}
+
+// ownGoroutineID returns goroutine id captured by the given error itself or 0 if there is none.
+func ownGoroutineID(err error) int {
+	if extErr, isExtErr := err.(*extendedError); isExtErr {
+		return extErr.metadata().goroutineID
+	}
+	return 0
+}
+
+// captureGoroutineID returns the id of the current goroutine or 0 if CaptureGoroutineID is disabled.
+func captureGoroutineID() int {
+	if !CaptureGoroutineID {
+		return 0
+	}
+	return currentGoroutineID()
+}
+
+// currentGoroutineID parses the id of the current goroutine from the header of its stack trace ("goroutine N [running]:").
+// It returns 0 if the header cannot be parsed.
+func currentGoroutineID() int {
+	var buf [64]byte
+	header := buf[:runtime.Stack(buf[:], false)]
+	header = bytes.TrimPrefix(header, []byte("goroutine "))
+	if spaceIndex := bytes.IndexByte(header, ' '); spaceIndex >= 0 {
+		header = header[:spaceIndex]
+	}
+	id, err := strconv.Atoi(string(header))
+	if err != nil {
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,54 @@
+package fail_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGoroutineID(t *testing.T) {
+	Convey("GetGoroutineID()", t, func() {
+		Convey("should return false if capturing is disabled", func() {
+			_, ok := fail.GetGoroutineID(fail.News("test"))
+			So(ok, ShouldBeFalse)
+			_, ok = fail.GetGoroutineID(errors.New("plain"))
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("with capturing enabled", func() {
+			fail.CaptureGoroutineID = true
+			defer func() { fail.CaptureGoroutineID = false }()
+
+			Convey("should return different ids for errors created in different goroutines", func() {
+				errs := make(chan error, 2)
+				for i := 0; i < 2; i++ {
+					go func() { errs <- fail.News("test") }()
+				}
+				id1, ok1 := fail.GetGoroutineID(<-errs)
+				id2, ok2 := fail.GetGoroutineID(<-errs)
+				So(ok1, ShouldBeTrue)
+				So(ok2, ShouldBeTrue)
+				So(id1, ShouldBeGreaterThan, 0)
+				So(id2, ShouldNotEqual, id1)
+			})
+
+			Convey("should return id of inner error", func() {
+				inner := fail.News("inner")
+				fail.CaptureGoroutineID = false
+				id, ok := fail.GetGoroutineID(fail.Wrap(inner, "outer"))
+				So(ok, ShouldBeTrue)
+				So(id, ShouldBeGreaterThan, 0)
+			})
+
+			Convey("GetFullDetails() should write goroutine header line", func() {
+				err := fail.News("test")
+				id, _ := fail.GetGoroutineID(err)
+				So(strings.HasPrefix(fail.GetFullDetails(err), fmt.Sprintf("goroutine %v:\n*errors.errorString: test", id)), ShouldBeTrue)
+			})
+		})
+	})
+}
@@ -0,0 +1,80 @@
+package fail
+
+// Keys of fields attached by WithRequestFields.
+const (
+	HTTPMethodFieldKey    = "http.method"
+	HTTPPathFieldKey      = "http.path"
+	HTTPRequestIDFieldKey = "http.request_id"
+)
+
+// HTTPStatuser is error which provides HTTP status code to respond with.
+//
+// HTTPStatus returns HTTP status code or zero if there is no status code.
+type HTTPStatuser interface {
+	error
+	HTTPStatus() int
+}
+
+func (extErr extendedError) HTTPStatus() int {
+	if status := extErr.metadata().httpStatus; status != 0 {
+		return status
+	}
+	if httpStatuser, isHTTPStatuser := extErr.originalError.(HTTPStatuser); isHTTPStatuser {
+		return httpStatuser.HTTPStatus()
+	}
+	return 0
+}
+
+// NewWithHTTPStatus creates a new error like New does and attaches the given HTTP status code to it.
+// Newly created error implements HTTPStatuser.
+func NewWithHTTPStatus(err error, status int, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	extErr := newExtendedError(err, nil, stackSkip)
+	extErr.writableMetadata().httpStatus = status
+	return enrich(extErr)
+}
+
+// GetHTTPStatus returns the first HTTP status code found in the chain of the given error
+// (the chain is walked the same way as in Is).
+// If there is no error implementing HTTPStatuser with non-zero status code then false is returned.
+func GetHTTPStatus(err error) (int, bool) {
+	var result int
+	walk(err, func(currErr error) bool {
+		if httpStatuser, isHTTPStatuser := currErr.(HTTPStatuser); isHTTPStatuser {
+			result = httpStatuser.HTTPStatus()
+		}
+		return result == 0
+	})
+	return result, result != 0
+}
+
+// HTTPStatusOr returns HTTP status code of the given error (see GetHTTPStatus) or fallback if there is no status code.
+func HTTPStatusOr(err error, fallback int) int {
+	if status, hasStatus := GetHTTPStatus(err); hasStatus {
+		return status
+	}
+	return fallback
+}
+
+// WithRequestFields returns an error with HTTP request method, path and id added to the fields of the given error
+// (under HTTPMethodFieldKey, HTTPPathFieldKey and HTTPRequestIDFieldKey) like WithField does.
+// It is intended for HTTP middlewares to attach request context to errors in a standard way.
+func WithRequestFields(err error, method, path, requestID string) error {
+	if err == nil {
+		return nil
+	}
+
+	return withFields(err, map[string]interface{}{
+		HTTPMethodFieldKey:    method,
+		HTTPPathFieldKey:      path,
+		HTTPRequestIDFieldKey: requestID,
+	}, 1)
+}
@@ -0,0 +1,63 @@
+package fail_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHTTP(t *testing.T) {
+	Convey("NewWithHTTPStatus() and GetHTTPStatus()", t, func() {
+		notFoundErr := fail.NewWithHTTPStatus(errors.New("user not found"), http.StatusNotFound)
+
+		Convey("should implement HTTPStatuser", func() {
+			So(notFoundErr.(fail.HTTPStatuser).HTTPStatus(), ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("should keep status after wrapping", func() {
+			err := fail.Wrap(fail.New(notFoundErr), "loading user")
+			status, ok := fail.GetHTTPStatus(err)
+			So(ok, ShouldBeTrue)
+			So(status, ShouldEqual, http.StatusNotFound)
+			So(fail.HTTPStatusOr(err, http.StatusInternalServerError), ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("should report absence of status", func() {
+			err := fail.Wrap(fail.News("test"), "loading user")
+			_, ok := fail.GetHTTPStatus(err)
+			So(ok, ShouldBeFalse)
+			So(fail.HTTPStatusOr(err, http.StatusInternalServerError), ShouldEqual, http.StatusInternalServerError)
+			So(fail.HTTPStatusOr(nil, http.StatusOK), ShouldEqual, http.StatusOK)
+		})
+	})
+
+	Convey("WithRequestFields()", t, func() {
+		err := fail.WithRequestFields(errors.New("user not found"), http.MethodGet, "/users/42", "req-1")
+		expectedFields := map[string]interface{}{
+			fail.HTTPMethodFieldKey:    http.MethodGet,
+			fail.HTTPPathFieldKey:      "/users/42",
+			fail.HTTPRequestIDFieldKey: "req-1",
+		}
+
+		Convey("should attach request fields", func() {
+			So(fail.GetFields(err), ShouldResemble, expectedFields)
+			So(fail.GetLocation(err), ShouldContainSubstring, "http_test.go")
+		})
+
+		Convey("should keep request fields after wrapping", func() {
+			So(fail.GetFields(fail.Wrap(err, "loading user")), ShouldResemble, expectedFields)
+		})
+
+		Convey("should keep location of error created by this package", func() {
+			extErr := fail.News("test")
+			So(fail.GetLocation(fail.WithRequestFields(extErr, http.MethodGet, "/", "req-2")), ShouldEqual, fail.GetLocation(extErr))
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(fail.WithRequestFields(nil, http.MethodGet, "/", "req-3"), ShouldBeNil)
+		})
+	})
+}
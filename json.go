@@ -0,0 +1,87 @@
+package fail
+
+import (
+	"encoding/json"
+)
+
+// errorJSON is JSON representation of an error.
+type errorJSON struct {
+	Message    string                 `json:"message"`
+	Type       string                 `json:"type,omitempty"`
+	Location   string                 `json:"location,omitempty"`
+	StackTrace []string               `json:"stackTrace,omitempty"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+	Inner      json.RawMessage        `json:"inner,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+// The result is an object with message, type, location, stack trace (as an array of frames),
+// fields (redacted, see RegisterRedactedFieldKey) and inner error (marshaled recursively by package function MarshalJSON).
+func (extErr extendedError) MarshalJSON() ([]byte, error) {
+	result := errorJSON{
+		Message:  extErr.Error(),
+		Type:     GetType(extErr).String(),
+		Location: extErr.Location(),
+		Fields:   RedactFields(extErr.Fields()),
+	}
+	for _, frame := range extErr.StackFrames() {
+		result.StackTrace = append(result.StackTrace, formatFrame(frame))
+	}
+	if inner := extErr.InnerError(); inner != nil {
+		innerJSON, err := MarshalJSON(inner)
+		if err != nil {
+			return nil, err
+		}
+		result.Inner = innerJSON
+	}
+	return json.Marshal(result)
+}
+
+// MarshalJSON returns JSON representation of any error.
+// If the given error implements json.Marshaler (like errors created by this package do) then it is used.
+// Otherwise the result is an object with error message only.
+// Nil error is marshaled as null.
+func MarshalJSON(err error) ([]byte, error) {
+	if err == nil {
+		return []byte("null"), nil
+	}
+	if marshaler, isMarshaler := err.(json.Marshaler); isMarshaler {
+		return marshaler.MarshalJSON()
+	}
+	return json.Marshal(errorJSON{Message: err.Error()})
+}
+
+// UnmarshalJSON reconstructs error from its JSON representation produced by MarshalJSON
+// (e.g. to analyze errors found in logs). Reconstructed error is *TransportError (see Decode)
+// with message, type, location, stack trace, fields and inner error (reconstructed recursively).
+// If the given data is not a JSON representation of an error (including null) then false is returned.
+func UnmarshalJSON(data []byte) (error, bool) {
+	dto, ok := unmarshalErrorDTO(data)
+	if !ok {
+		return nil, false
+	}
+	return Decode(dto), true
+}
+
+func unmarshalErrorDTO(data []byte) (*ErrorDTO, bool) {
+	var errJSON *errorJSON
+	if err := json.Unmarshal(data, &errJSON); err != nil || errJSON == nil {
+		return nil, false
+	}
+
+	dto := &ErrorDTO{
+		Message:  errJSON.Message,
+		Type:     errJSON.Type,
+		Location: errJSON.Location,
+		Stack:    errJSON.StackTrace,
+		Fields:   errJSON.Fields,
+	}
+	if len(errJSON.Inner) > 0 && string(errJSON.Inner) != "null" {
+		inner, ok := unmarshalErrorDTO(errJSON.Inner)
+		if !ok {
+			return nil, false
+		}
+		dto.Inner = inner
+	}
+	return dto, true
+}
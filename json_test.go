@@ -0,0 +1,87 @@
+package fail_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestJSON(t *testing.T) {
+	Convey("MarshalJSON()", t, func() {
+		Convey("should marshal plain error as message only", func() {
+			data, err := fail.MarshalJSON(errors.New("plain"))
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, `{"message":"plain"}`)
+		})
+
+		Convey("should marshal nil error as null", func() {
+			data, err := fail.MarshalJSON(nil)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "null")
+		})
+
+		Convey("should marshal two-level composite error", func() {
+			outer := fail.NewWithFields(errors.New("outer"), map[string]interface{}{"userID": 42})
+			inner := fail.News("inner")
+			composite := fail.NewWithInner(outer, inner)
+			data, err := json.Marshal(composite)
+			So(err, ShouldBeNil)
+			dataByFunc, err := fail.MarshalJSON(composite)
+			So(err, ShouldBeNil)
+			So(string(dataByFunc), ShouldEqual, string(data))
+
+			var result map[string]interface{}
+			So(json.Unmarshal(data, &result), ShouldBeNil)
+			So(result["message"], ShouldEqual, "outer")
+			So(result["type"], ShouldEqual, "*errors.errorString")
+			So(result["location"], ShouldContainSubstring, "json_test.go:")
+			So(result["fields"], ShouldResemble, map[string]interface{}{"userID": float64(42)})
+			stackTrace := result["stackTrace"].([]interface{})
+			So(len(stackTrace), ShouldBeGreaterThan, 0)
+			So(stackTrace[0], ShouldContainSubstring, "json_test.go")
+
+			innerResult := result["inner"].(map[string]interface{})
+			So(innerResult["message"], ShouldEqual, "inner")
+			So(innerResult["type"], ShouldEqual, "*errors.errorString")
+			So(innerResult["location"], ShouldContainSubstring, "json_test.go:")
+			So(innerResult, ShouldNotContainKey, "fields")
+			So(innerResult, ShouldNotContainKey, "inner")
+		})
+	})
+
+	Convey("UnmarshalJSON()", t, func() {
+		Convey("should reconstruct marshaled error", func() {
+			inner := fail.News("inner")
+			err := fail.NewWithInner(fail.NewWithFields(errors.New("outer"), map[string]interface{}{"userID": 42}), inner)
+			data, marshalErr := fail.MarshalJSON(err)
+			So(marshalErr, ShouldBeNil)
+
+			unmarshaled, ok := fail.UnmarshalJSON(data)
+			So(ok, ShouldBeTrue)
+			So(unmarshaled.Error(), ShouldEqual, "outer")
+			So(fail.GetFields(unmarshaled), ShouldResemble, map[string]interface{}{"userID": 42.0})
+			So(fail.GetLocation(unmarshaled), ShouldEqual, fail.GetLocation(err))
+			So(fail.GetStackTrace(unmarshaled), ShouldEqual, fail.GetStackTrace(err))
+			So(fail.GetInner(unmarshaled).Error(), ShouldEqual, "inner")
+			So(fail.GetStackTrace(fail.GetInner(unmarshaled)), ShouldEqual, fail.GetStackTrace(inner))
+			So(fail.GetFullDetails(unmarshaled), ShouldEqual, fail.GetFullDetails(err))
+		})
+
+		Convey("should reconstruct plain error", func() {
+			unmarshaled, ok := fail.UnmarshalJSON([]byte(`{"message":"plain"}`))
+			So(ok, ShouldBeTrue)
+			So(unmarshaled.Error(), ShouldEqual, "plain")
+			So(fail.GetInner(unmarshaled), ShouldBeNil)
+		})
+
+		Convey("should report invalid data", func() {
+			for _, data := range []string{"null", "", "[]", `{"message":"outer","inner":[]}`} {
+				_, ok := fail.UnmarshalJSON([]byte(data))
+				So(ok, ShouldBeFalse)
+			}
+		})
+	})
+}
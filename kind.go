@@ -0,0 +1,93 @@
+package fail
+
+import "fmt"
+
+// Kind is the category of error used for uniform handling of errors (e.g. mapping them to responses).
+// Zero value KindUnknown means that kind is not specified.
+type Kind int
+
+// Supported kinds.
+const (
+	KindUnknown Kind = iota
+	KindNotFound
+	KindInvalid
+	KindConflict
+	KindInternal
+)
+
+func (kind Kind) String() string {
+	switch kind {
+	case KindUnknown:
+		return "unknown"
+	case KindNotFound:
+		return "not found"
+	case KindInvalid:
+		return "invalid"
+	case KindConflict:
+		return "conflict"
+	case KindInternal:
+		return "internal"
+	}
+	return fmt.Sprintf("Kind(%d)", int(kind))
+}
+
+// ErrorWithKind is error which provides its kind.
+//
+// Kind returns error's kind or KindUnknown if kind is not specified.
+type ErrorWithKind interface {
+	error
+	Kind() Kind
+}
+
+func (extErr extendedError) Kind() Kind {
+	if kind := extErr.metadata().kind; kind != KindUnknown {
+		return kind
+	}
+	if errWithKind, isErrWithKind := extErr.originalError.(ErrorWithKind); isErrWithKind {
+		return errWithKind.Kind()
+	}
+	return KindUnknown
+}
+
+// NewWithKind creates a new error like New does and attaches the given kind to it.
+// Newly created error implements ErrorWithKind.
+func NewWithKind(err error, kind Kind, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	extErr := newExtendedError(err, nil, stackSkip)
+	extErr.writableMetadata().kind = kind
+	return enrich(extErr)
+}
+
+// GetKind returns the kind of the nearest error having one found in the chain of the given error
+// (the chain is walked the same way as in Is). If there is no such error then KindUnknown is returned.
+func GetKind(err error) Kind {
+	result := KindUnknown
+	walk(err, func(currErr error) bool {
+		if errWithKind, isErrWithKind := currErr.(ErrorWithKind); isErrWithKind {
+			result = errWithKind.Kind()
+		}
+		return result == KindUnknown
+	})
+	return result
+}
+
+// IsKind checks if any error in the chain of the given error (the chain is walked the same way as in Is)
+// has the given kind.
+func IsKind(err error, kind Kind) bool {
+	found := false
+	walk(err, func(currErr error) bool {
+		if errWithKind, isErrWithKind := currErr.(ErrorWithKind); isErrWithKind {
+			found = errWithKind.Kind() == kind
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,58 @@
+package fail_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestKind(t *testing.T) {
+	Convey("Kind", t, func() {
+		Convey("should have string representation", func() {
+			So(fail.KindNotFound.String(), ShouldEqual, "not found")
+			So(fail.KindUnknown.String(), ShouldEqual, "unknown")
+			So(fail.Kind(100).String(), ShouldEqual, "Kind(100)")
+		})
+	})
+
+	Convey("NewWithKind() and GetKind()", t, func() {
+		Convey("should return kind of error", func() {
+			err := fail.NewWithKind(errors.New("user not found"), fail.KindNotFound)
+			So(err.(fail.ErrorWithKind).Kind(), ShouldEqual, fail.KindNotFound)
+			So(fail.GetKind(err), ShouldEqual, fail.KindNotFound)
+			So(fail.GetLocation(err), ShouldContainSubstring, "kind_test.go")
+		})
+
+		Convey("should propagate kind through wrapping", func() {
+			inner := fail.NewWithKind(errors.New("user not found"), fail.KindNotFound)
+			So(fail.GetKind(fail.New(inner)), ShouldEqual, fail.KindNotFound)
+			So(fail.GetKind(fail.Wrap(inner, "loading user")), ShouldEqual, fail.KindNotFound)
+			So(fail.GetKind(fail.NewWithInner(errors.New("outer"), inner)), ShouldEqual, fail.KindNotFound)
+		})
+
+		Convey("should return the nearest kind", func() {
+			inner := fail.NewWithKind(errors.New("user not found"), fail.KindNotFound)
+			err := fail.NewWithKind(fail.Wrap(inner, "loading user"), fail.KindInternal)
+			So(fail.GetKind(err), ShouldEqual, fail.KindInternal)
+		})
+
+		Convey("should return KindUnknown if there is no kind", func() {
+			So(fail.GetKind(fail.Wrap(errors.New("test"), "test")), ShouldEqual, fail.KindUnknown)
+			So(fail.GetKind(nil), ShouldEqual, fail.KindUnknown)
+		})
+	})
+
+	Convey("IsKind()", t, func() {
+		inner := fail.NewWithKind(errors.New("user not found"), fail.KindNotFound)
+		err := fail.NewWithKind(fail.Wrap(inner, "loading user"), fail.KindInternal)
+
+		Convey("should check kinds across the chain", func() {
+			So(fail.IsKind(err, fail.KindInternal), ShouldBeTrue)
+			So(fail.IsKind(err, fail.KindNotFound), ShouldBeTrue)
+			So(fail.IsKind(err, fail.KindConflict), ShouldBeFalse)
+			So(fail.IsKind(errors.New("test"), fail.KindNotFound), ShouldBeFalse)
+		})
+	})
+}
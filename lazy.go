@@ -0,0 +1,149 @@
+package fail
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+
+	"gopkg.in/stack.v1"
+)
+
+// NewLazy creates a new error like New does, but captures stack trace lazily.
+// Only program counters are stored at creation and stack trace frames are built on first demand
+// (e.g. by StackTrace or StackFrames), which makes creation of errors whose stack trace is never read cheaper.
+func NewLazy(err error, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	if !CaptureStackTraces {
+		return NewLocationOnly(err, stackSkip)
+	}
+	if stackCapturer != nil {
+		// stack trace replaced by SetStackCapturer cannot be captured lazily
+		return enrich(newExtendedError(err, nil, stackSkip))
+	}
+
+	var pcs [maxLazyStackDepth]uintptr
+	// runtime.Callers counts itself, so one more frame has to be skipped comparing to stack.Caller
+	n := runtime.Callers(stackSkip+1, pcs[:])
+	return enrich(&extendedError{
+		originalError: err,
+		location:      stack.Caller(stackSkip),
+		lazyStack:     &lazyStackTrace{pcs: append([]uintptr(nil), pcs[:n]...)},
+		meta:          captureMetadata(),
+	})
+}
+
+// NewLocationOnly creates a new error like New does, but captures only its location without stack trace.
+// It is intended for very frequent errors whose stack trace is never read:
+// GetLocation works for such errors while their own stack trace is empty.
+func NewLocationOnly(err error, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	return enrich(&extendedError{
+		originalError: err,
+		location:      captureLocation(stackSkip),
+		meta:          captureMetadata(),
+	})
+}
+
+// maxLazyStackDepth is the maximum number of program counters captured for lazy stack trace.
+const maxLazyStackDepth = 512
+
+// lazyStackTrace is stack trace which is captured as program counters and converted to frames on first demand.
+type lazyStackTrace struct {
+	once   sync.Once
+	pcs    []uintptr
+	frames []Frame
+}
+
+// Frames returns stack trace frames building them on first call.
+// Frames are formatted and trimmed the same way as frames of eagerly captured stack trace.
+func (lazyStack *lazyStackTrace) Frames() []Frame {
+	lazyStack.once.Do(func() {
+		var runtimeFrames []runtime.Frame
+		callersFrames := runtime.CallersFrames(lazyStack.pcs)
+		for {
+			runtimeFrame, more := callersFrames.Next()
+			runtimeFrames = append(runtimeFrames, runtimeFrame)
+			if !more {
+				break
+			}
+		}
+		for len(runtimeFrames) > 0 && isRuntimeFrame(runtimeFrames[len(runtimeFrames)-1]) {
+			runtimeFrames = runtimeFrames[:len(runtimeFrames)-1]
+		}
+		if MaxStackDepth > 0 && len(runtimeFrames) > MaxStackDepth {
+			runtimeFrames = runtimeFrames[:MaxStackDepth]
+		}
+
+		if len(runtimeFrames) > 0 {
+			lazyStack.frames = make([]Frame, len(runtimeFrames))
+			for i, runtimeFrame := range runtimeFrames {
+				lazyStack.frames[i] = runtimeFrameToFrame(runtimeFrame)
+			}
+		}
+		lazyStack.pcs = nil
+	})
+	return lazyStack.frames
+}
+
+// runtimeFrameToFrame converts runtime.Frame to Frame the same way as callToFrame does for stack.Call.
+func runtimeFrameToFrame(runtimeFrame runtime.Frame) Frame {
+	const pathSep = "/"
+	const pkgSep = "."
+
+	// file path keeps one more path segment than function name has (see stack.Call %+s)
+	file := runtimeFrame.File
+	i := len(file)
+	for n := strings.Count(runtimeFrame.Function, pathSep) + 2; n > 0; n-- {
+		if i = strings.LastIndex(file[:i], pathSep); i == -1 {
+			break
+		}
+	}
+	file = file[i+len(pathSep):]
+
+	// function name is without package path (see stack.Call %n)
+	function := runtimeFrame.Function
+	if i := strings.LastIndex(function, pathSep); i != -1 {
+		function = function[i+len(pathSep):]
+	}
+	if i := strings.Index(function, pkgSep); i != -1 {
+		function = function[i+len(pkgSep):]
+	}
+
+	return markUnknownFrameInfo(Frame{File: file, Line: runtimeFrame.Line, Function: function, Package: getFunctionPackage(runtimeFrame.Function)}, runtimeFrame)
+}
+
+// goroot is GOROOT source directory determined from location of runtime package.
+var goroot = func() string {
+	var pcs [1]uintptr
+	runtime.Callers(0, pcs[:])
+	runtimeFrame, _ := runtime.CallersFrames(pcs[:]).Next()
+	if i := strings.LastIndex(runtimeFrame.File, "/runtime/"); i != -1 {
+		return runtimeFrame.File[:i+1]
+	}
+	return ""
+}()
+
+// isRuntimeFrame checks if the frame belongs to Go runtime the same way as stack.CallStack.TrimRuntime does.
+func isRuntimeFrame(runtimeFrame runtime.Frame) bool {
+	file := runtimeFrame.File
+	if len(file) == 0 || file[0] == '?' {
+		return true
+	}
+	return (goroot != "" && strings.HasPrefix(file, goroot)) || strings.HasSuffix(file, "/_testmain.go")
+}
@@ -0,0 +1,92 @@
+package fail_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLazy(t *testing.T) {
+	Convey("NewLazy()", t, func() {
+		originalErr := errors.New("test")
+		eagerErr, lazyErr := fail.New(originalErr), fail.NewLazy(originalErr)
+
+		Convey("should keep original error", func() {
+			So(lazyErr.Error(), ShouldEqual, "test")
+			So(fail.GetOriginalError(lazyErr), ShouldEqual, originalErr)
+		})
+
+		Convey("should have the same location as eager error", func() {
+			So(fail.GetLocation(lazyErr), ShouldEqual, fail.GetLocation(eagerErr))
+		})
+
+		Convey("should have the same stack trace as eager error", func() {
+			So(fail.GetStackFrames(lazyErr), ShouldResemble, fail.GetStackFrames(eagerErr))
+			So(fail.GetStackTrace(lazyErr), ShouldEqual, fail.GetStackTrace(eagerErr))
+			So(fileName(fail.GetStackFrames(lazyErr)[0]), ShouldEqual, "lazy_test.go")
+			So(fail.GetStackFrames(lazyErr)[0].Package, ShouldEqual, "github.com/nbgo/fail_test")
+		})
+
+		Convey("should honor MaxStackDepth", func() {
+			fail.MaxStackDepth = 2
+			defer func() { fail.MaxStackDepth = 0 }()
+			So(len(fail.GetStackFrames(fail.NewLazy(originalErr))), ShouldEqual, 2)
+		})
+	})
+
+	Convey("NewLocationOnly()", t, func() {
+		originalErr := errors.New("test")
+		eagerErr, locationOnlyErr := fail.New(originalErr), fail.NewLocationOnly(originalErr)
+
+		Convey("should have the same location as eager error", func() {
+			So(fail.GetLocation(locationOnlyErr), ShouldEqual, fail.GetLocation(eagerErr))
+			So(fail.GetLocation(locationOnlyErr), ShouldContainSubstring, "lazy_test.go")
+		})
+
+		Convey("should have no stack trace", func() {
+			So(fail.GetStackTrace(locationOnlyErr), ShouldBeEmpty)
+			So(fail.GetStackFrames(locationOnlyErr), ShouldBeEmpty)
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(fail.NewLocationOnly(nil), ShouldBeNil)
+		})
+	})
+}
+
+func BenchmarkNew(b *testing.B) {
+	err := errors.New("test")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = fail.New(err)
+	}
+}
+
+func BenchmarkNewLazy(b *testing.B) {
+	err := errors.New("test")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = fail.NewLazy(err)
+	}
+}
+
+func BenchmarkNewLocationOnly(b *testing.B) {
+	err := errors.New("test")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = fail.NewLocationOnly(err)
+	}
+}
+
+func BenchmarkNewWithoutStackTraces(b *testing.B) {
+	fail.CaptureStackTraces = false
+	defer func() { fail.CaptureStackTraces = true }()
+
+	err := errors.New("test")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = fail.New(err)
+	}
+}
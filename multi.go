@@ -0,0 +1,108 @@
+package fail
+
+import (
+	"strings"
+	"sync"
+)
+
+// MultiError is error which aggregates several errors.
+// Implements Unwrap() []error, so Is and As (as well as errors.Is and errors.As) match against any of its errors.
+type MultiError struct {
+	errs []error
+}
+
+// Error returns messages of all aggregated errors separated by semicolon.
+func (multiErr *MultiError) Error() string {
+	messages := make([]string, len(multiErr.errs))
+	for i, err := range multiErr.errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Errors returns aggregated errors.
+func (multiErr *MultiError) Errors() []error {
+	return append([]error(nil), multiErr.errs...)
+}
+
+// Unwrap returns aggregated errors.
+func (multiErr *MultiError) Unwrap() []error {
+	return multiErr.Errors()
+}
+
+// Join aggregates the given errors skipping nil ones.
+// It returns nil if there are no non-nil errors, the error itself if there is only one non-nil error
+// and *MultiError otherwise.
+func Join(errs ...error) error {
+	var nonNilErrs []error
+	for _, err := range errs {
+		if err != nil {
+			nonNilErrs = append(nonNilErrs, err)
+		}
+	}
+
+	switch len(nonNilErrs) {
+	case 0:
+		return nil
+	case 1:
+		return nonNilErrs[0]
+	}
+	return &MultiError{nonNilErrs}
+}
+
+// ErrorList accumulates errors incrementally, e.g. in a loop.
+// It is safe for concurrent use.
+type ErrorList struct {
+	mutex sync.Mutex
+	errs  []error
+}
+
+// NewErrorList creates empty error list.
+func NewErrorList() *ErrorList {
+	return &ErrorList{}
+}
+
+// Add appends the given error to the list, nil error is ignored.
+func (errList *ErrorList) Add(err error) {
+	if err == nil {
+		return
+	}
+
+	errList.mutex.Lock()
+	defer errList.mutex.Unlock()
+
+	errList.errs = append(errList.errs, err)
+}
+
+// Len returns the number of accumulated errors.
+func (errList *ErrorList) Len() int {
+	errList.mutex.Lock()
+	defer errList.mutex.Unlock()
+
+	return len(errList.errs)
+}
+
+// ErrorOrNil returns accumulated errors joined like Join does:
+// nil if there are no errors, the error itself if there is only one error and *MultiError otherwise.
+func (errList *ErrorList) ErrorOrNil() error {
+	errList.mutex.Lock()
+	defer errList.mutex.Unlock()
+
+	return Join(errList.errs...)
+}
+
+// BatchIndexFieldKey is the key of field with index of the operation which failed in Batch.
+const BatchIndexFieldKey = "batch.index"
+
+// Batch runs all the given operations and returns their errors joined like Join does
+// (so nil is returned if all operations succeed).
+// Each error gets field BatchIndexFieldKey with index of the operation which returned it (see WithField).
+func Batch(ops []func() error) error {
+	var errs []error
+	for i, op := range ops {
+		if err := op(); err != nil {
+			errs = append(errs, withFields(err, map[string]interface{}{BatchIndexFieldKey: i}, 1))
+		}
+	}
+	return Join(errs...)
+}
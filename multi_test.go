@@ -0,0 +1,143 @@
+package fail_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMulti(t *testing.T) {
+	Convey("Join()", t, func() {
+		err1 := errors.New("error 1")
+		err2 := fail.New(&MyError{"error 2", nil})
+
+		Convey("should return nil if all errors are nil", func() {
+			So(fail.Join(), ShouldBeNil)
+			So(fail.Join(nil, nil), ShouldBeNil)
+		})
+
+		Convey("should return single non-nil error", func() {
+			So(fail.Join(nil, err1, nil), ShouldEqual, err1)
+		})
+
+		Convey("should aggregate several non-nil errors", func() {
+			err := fail.Join(err1, nil, err2)
+			multiErr, isMultiErr := err.(*fail.MultiError)
+			So(isMultiErr, ShouldBeTrue)
+			So(multiErr.Errors(), ShouldResemble, []error{err1, err2})
+			So(multiErr.Unwrap(), ShouldResemble, []error{err1, err2})
+			So(err.Error(), ShouldEqual, "error 1; MyError: error 2. Reason: <nil>")
+
+			Convey("which should be matched by Is and As", func() {
+				So(fail.Is(err, err1), ShouldBeTrue)
+				So(fail.Is(err, err2), ShouldBeTrue)
+				So(fail.Is(err, errors.New("error 1")), ShouldBeFalse)
+				So(errors.Is(err, err1), ShouldBeTrue)
+
+				var myErr *MyError
+				So(fail.As(err, &myErr), ShouldBeTrue)
+				So(myErr.msg, ShouldEqual, "error 2")
+			})
+
+			Convey("which should be rendered by GetFullDetails with each error", func() {
+				details := fail.GetFullDetails(fail.New(err))
+				So(details, ShouldStartWith, "*fail.MultiError: error 1; MyError: error 2. Reason: <nil>\n")
+				So(details, ShouldContainSubstring, "\n"+fail.DetailsIndent+"[0] *errors.errorString: error 1\n")
+				So(details, ShouldContainSubstring, "\n"+fail.DetailsIndent+"[1] *fail_test.MyError: MyError: error 2. Reason: <nil>\n")
+				So(details, ShouldContainSubstring, "\n"+fail.DetailsIndent+fail.DetailsIndent+fail.GetLocation(err2)+"\n")
+			})
+
+			Convey("which should be encoded with each error", func() {
+				extErr := fail.New(err)
+				dto := fail.Encode(extErr)
+				So(dto.Errors, ShouldHaveLength, 2)
+				So(dto.Errors[0].Message, ShouldEqual, "error 1")
+				So(dto.Errors[1].Location, ShouldEqual, fail.GetLocation(err2))
+
+				snapshot := fail.Snapshot(extErr)
+				So(fail.GetFullDetails(snapshot), ShouldEqual, fail.GetFullDetails(extErr))
+				So(snapshot.(*fail.TransportError).Errors(), ShouldHaveLength, 2)
+			})
+		})
+	})
+
+	Convey("ErrorList", t, func() {
+		errList := fail.NewErrorList()
+		err1, err2 := errors.New("error 1"), errors.New("error 2")
+
+		Convey("should return nil if there are no errors", func() {
+			errList.Add(nil)
+			So(errList.Len(), ShouldEqual, 0)
+			So(errList.ErrorOrNil(), ShouldBeNil)
+		})
+
+		Convey("should return single error", func() {
+			errList.Add(nil)
+			errList.Add(err1)
+			So(errList.ErrorOrNil(), ShouldEqual, err1)
+		})
+
+		Convey("should aggregate several errors", func() {
+			errList.Add(err1)
+			errList.Add(err2)
+			So(errList.Len(), ShouldEqual, 2)
+			err := errList.ErrorOrNil()
+			So(err.(*fail.MultiError).Errors(), ShouldResemble, []error{err1, err2})
+
+			Convey("which should not be affected by further additions", func() {
+				errList.Add(err1)
+				So(err.(*fail.MultiError).Errors(), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("should support concurrent additions", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 100; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errList.Add(err1)
+				}()
+			}
+			wg.Wait()
+			So(errList.Len(), ShouldEqual, 100)
+		})
+	})
+
+	Convey("Batch()", t, func() {
+		succeed := func() error { return nil }
+		err1, err2 := errors.New("error 1"), fail.News("error 2")
+
+		Convey("should return nil if all operations succeed", func() {
+			So(fail.Batch([]func() error{succeed, succeed}), ShouldBeNil)
+			So(fail.Batch(nil), ShouldBeNil)
+		})
+
+		Convey("should collect errors with indexes of failed operations", func() {
+			err := fail.Batch([]func() error{
+				succeed,
+				func() error { return err1 },
+				succeed,
+				func() error { return err2 },
+			})
+			errs := err.(*fail.MultiError).Errors()
+			So(errs, ShouldHaveLength, 2)
+			So(fail.GetFields(errs[0]), ShouldResemble, map[string]interface{}{fail.BatchIndexFieldKey: 1})
+			So(fail.GetFields(errs[1]), ShouldResemble, map[string]interface{}{fail.BatchIndexFieldKey: 3})
+			So(fail.Is(err, err1), ShouldBeTrue)
+			So(fail.GetLocation(errs[0]), ShouldContainSubstring, "multi_test.go")
+			So(fail.GetLocation(errs[1]), ShouldEqual, fail.GetLocation(err2))
+			So(fail.GetFullDetails(err), ShouldContainSubstring, "[1] *errors.errorString: error 2\n"+
+				fail.DetailsIndent+fail.DetailsIndent+"fields: "+fail.BatchIndexFieldKey+"=3\n")
+		})
+
+		Convey("should return single error as is", func() {
+			err := fail.Batch([]func() error{func() error { return err1 }})
+			So(fail.Is(err, err1), ShouldBeTrue)
+			So(fail.GetFields(err), ShouldResemble, map[string]interface{}{fail.BatchIndexFieldKey: 0})
+		})
+	})
+}
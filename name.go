@@ -0,0 +1,47 @@
+package fail
+
+// ErrorWithName is error which provides a human-friendly name of its type.
+// GetFullDetails prints the name instead of the type of error (see GetType).
+//
+// ErrorName returns error's name or empty string if there is no name.
+type ErrorWithName interface {
+	error
+	ErrorName() string
+}
+
+func (extErr extendedError) ErrorName() string {
+	if name := extErr.metadata().name; name != "" {
+		return name
+	}
+	if errWithName, isErrWithName := extErr.originalError.(ErrorWithName); isErrWithName {
+		return errWithName.ErrorName()
+	}
+	return ""
+}
+
+// NewNamed creates a new error like New does and attaches the given name to it.
+// Newly created error implements ErrorWithName.
+func NewNamed(err error, name string, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	extErr := newExtendedError(err, nil, stackSkip)
+	extErr.writableMetadata().name = name
+	return enrich(extErr)
+}
+
+// getTypeName returns the name of the given error if it implements ErrorWithName or the name of its type otherwise.
+func getTypeName(err error) string {
+	if errWithName, isErrWithName := err.(ErrorWithName); isErrWithName {
+		if name := errWithName.ErrorName(); name != "" {
+			return name
+		}
+	}
+	return GetType(err).String()
+}
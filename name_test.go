@@ -0,0 +1,46 @@
+package fail_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type namedError struct{}
+
+func (namedError) Error() string     { return "named" }
+func (namedError) ErrorName() string { return "NamedError" }
+
+func TestNamedErrors(t *testing.T) {
+	Convey("NewNamed()", t, func() {
+		err := fail.NewNamed(errors.New("not found"), "NotFound")
+
+		Convey("should implement ErrorWithName", func() {
+			So(err.(fail.ErrorWithName).ErrorName(), ShouldEqual, "NotFound")
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(fail.NewNamed(nil, "NotFound"), ShouldBeNil)
+		})
+
+		Convey("GetFullDetails() should print name instead of type", func() {
+			details := fail.GetFullDetails(fail.NewWithInner(fail.News("outer"), err))
+			So(details, ShouldContainSubstring, "\nNotFound: not found")
+			So(details, ShouldNotContainSubstring, "*errors.errorString: not found")
+			So(details, ShouldNotContainSubstring, "extendedError")
+		})
+	})
+
+	Convey("GetFullDetails()", t, func() {
+		Convey("should print name of custom error implementing ErrorWithName", func() {
+			So(strings.HasPrefix(fail.GetFullDetails(fail.New(namedError{})), "NamedError: named"), ShouldBeTrue)
+		})
+
+		Convey("should print type if there is no name", func() {
+			So(strings.HasPrefix(fail.GetFullDetails(fail.News("test")), "*errors.errorString: test"), ShouldBeTrue)
+		})
+	})
+}
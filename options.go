@@ -0,0 +1,82 @@
+package fail
+
+// Option configures error created by NewWith.
+type Option func(*newWithOptions)
+
+type newWithOptions struct {
+	inner     error
+	stackSkip int
+	code      string
+	fields    map[string]interface{}
+	severity  Severity
+	sealed    bool
+}
+
+// Code sets code of error created by NewWith (see NewWithCode).
+func Code(code string) Option {
+	return func(options *newWithOptions) {
+		options.code = code
+	}
+}
+
+// Fields adds fields to error created by NewWith (see NewWithFields).
+func Fields(fields map[string]interface{}) Option {
+	return func(options *newWithOptions) {
+		options.fields = mergeFields(options.fields, fields)
+	}
+}
+
+// Inner sets inner error of error created by NewWith (see NewWithInner).
+func Inner(inner error) Option {
+	return func(options *newWithOptions) {
+		options.inner = inner
+	}
+}
+
+// WithSeverity sets severity of error created by NewWith (see NewWithSeverity).
+// It is not named Severity as there is type Severity already.
+func WithSeverity(severity Severity) Option {
+	return func(options *newWithOptions) {
+		options.severity = severity
+	}
+}
+
+// SealMetadata seals metadata of error created by NewWith: fields, code and tags of the errors it wraps
+// (both original and inner ones) are not inherited by it and are hidden from GetFields, GetAllFields, GetCode and GetTags,
+// which stop walking the chain at it. The error itself and its chain are left intact for Is, As and GetFullDetails.
+// It is intended for sanitized errors on boundaries (e.g. returned to clients).
+func SealMetadata() Option {
+	return func(options *newWithOptions) {
+		options.sealed = true
+	}
+}
+
+// Skip sets additional stack skip of error created by NewWith (see New).
+func Skip(additionalStackSkip int) Option {
+	return func(options *newWithOptions) {
+		options.stackSkip = additionalStackSkip
+	}
+}
+
+// NewWith creates a new error like New does and attaches metadata defined by the given options to it,
+// e.g. fail.NewWith(err, fail.Code("NOT_FOUND"), fail.Fields(fields), fail.Inner(cause)).
+// Options are applied in order, so the latter ones win (except Fields which are merged).
+func NewWith(err error, opts ...Option) error {
+	if err == nil {
+		return nil
+	}
+
+	var options newWithOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	extErr := newExtendedError(err, options.inner, 1+options.stackSkip)
+	extErr.code = options.code
+	extErr.fields = options.fields
+	if options.severity != 0 {
+		extErr.writableMetadata().severity = options.severity
+	}
+	extErr.sealed = options.sealed
+	return enrich(extErr)
+}
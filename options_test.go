@@ -0,0 +1,85 @@
+package fail_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewWith(t *testing.T) {
+	Convey("NewWith()", t, func() {
+		Convey("should combine code, fields, inner error and severity", func() {
+			inner := errors.New("connection refused")
+			err := fail.NewWith(errors.New("cannot load user"),
+				fail.Code("UNAVAILABLE"),
+				fail.Fields(map[string]interface{}{"id": 42}),
+				fail.Fields(map[string]interface{}{"table": "users"}),
+				fail.Inner(inner),
+				fail.WithSeverity(fail.SeverityCritical),
+			)
+			So(err.Error(), ShouldEqual, "cannot load user")
+			So(fail.GetCode(err), ShouldEqual, "UNAVAILABLE")
+			So(fail.GetFields(err), ShouldResemble, map[string]interface{}{"id": 42, "table": "users"})
+			So(fail.GetInner(err), ShouldEqual, inner)
+			So(fail.Is(err, inner), ShouldBeTrue)
+			severity, _ := fail.GetSeverity(err)
+			So(severity, ShouldEqual, fail.SeverityCritical)
+			So(fail.GetLocation(err), ShouldContainSubstring, "options_test.go")
+		})
+
+		Convey("should work like New without options", func() {
+			err := fail.NewWith(errors.New("test"))
+			So(fail.GetCode(err), ShouldBeEmpty)
+			So(fail.GetFields(err), ShouldBeNil)
+			So(fail.GetInner(err), ShouldBeNil)
+			So(fail.GetStackTrace(err), ShouldNotBeEmpty)
+		})
+
+		Convey("should honor additional stack skip", func() {
+			line := fail.GetStackFrames(fail.News("next line"))[0].Line + 1
+			err := newWithInHelper()
+			So(fail.GetLocation(err), ShouldContainSubstring, fmt.Sprintf("options_test.go:%d ", line))
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(fail.NewWith(nil, fail.Code("TEST")), ShouldBeNil)
+		})
+	})
+
+	Convey("SealMetadata()", t, func() {
+		inner := fail.WithTag(fail.NewWithCode(fail.WithField(errors.New("query failed"), "sql", "SELECT 1"), "DB_ERROR"), "internal")
+		sealed := fail.NewWith(fail.WithField(errors.New("internal error"), "requestID", "req-1"),
+			fail.Inner(inner), fail.Fields(map[string]interface{}{"userID": 42}), fail.SealMetadata())
+
+		Convey("should hide metadata of wrapped errors", func() {
+			So(fail.GetAllFields(sealed), ShouldResemble, map[string]interface{}{"userID": 42})
+			So(fail.GetFields(sealed), ShouldResemble, map[string]interface{}{"userID": 42})
+			So(fail.GetCode(sealed), ShouldBeEmpty)
+			So(fail.GetTags(sealed), ShouldBeNil)
+		})
+
+		Convey("should keep own code and outer metadata", func() {
+			err := fail.WithField(fail.NewWith(errors.New("internal error"), fail.Inner(inner), fail.Code("INTERNAL"), fail.SealMetadata()), "outer", true)
+			So(fail.GetCode(err), ShouldEqual, "INTERNAL")
+			So(fail.GetAllFields(err), ShouldResemble, map[string]interface{}{"outer": true})
+		})
+
+		Convey("should keep the chain for Is", func() {
+			So(fail.Is(sealed, inner), ShouldBeTrue)
+			So(fail.GetInner(sealed), ShouldEqual, inner)
+		})
+
+		Convey("should not affect unsealed errors", func() {
+			err := fail.NewWith(errors.New("internal error"), fail.Inner(inner))
+			So(fail.GetCode(err), ShouldEqual, "DB_ERROR")
+			So(fail.GetAllFields(err), ShouldResemble, map[string]interface{}{"sql": "SELECT 1"})
+		})
+	})
+}
+
+func newWithInHelper() error {
+	return fail.NewWith(errors.New("test"), fail.Skip(1))
+}
@@ -0,0 +1,43 @@
+package fail_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOriginatedIn(t *testing.T) {
+	Convey("OriginatedIn()", t, func() {
+		// error created in a callback is created below frames of the package calling it
+		var errInSort error
+		values := []int{2, 1}
+		sort.Slice(values, func(i, j int) bool {
+			if errInSort == nil {
+				errInSort = fail.News("comparison failed")
+			}
+			return values[i] < values[j]
+		})
+
+		Convey("should check packages of stack trace frames", func() {
+			So(fail.OriginatedIn(errInSort, "sort"), ShouldBeTrue)
+			So(fail.OriginatedIn(errInSort, "github.com/nbgo/fail_test"), ShouldBeTrue)
+			So(fail.OriginatedIn(errInSort, "net/http"), ShouldBeFalse)
+			So(fail.OriginatedIn(fail.News("test"), "sort"), ShouldBeFalse)
+		})
+
+		Convey("should check the whole chain", func() {
+			// stack trace of the wrapper itself has no frames of sort package
+			So(fail.OriginatedIn(fail.Wrap(errors.New("test"), "sorting"), "sort"), ShouldBeFalse)
+			So(fail.OriginatedIn(fail.Wrap(errInSort, "sorting"), "sort"), ShouldBeTrue)
+		})
+
+		Convey("should check location of error without stack trace", func() {
+			err := fail.NewLocationOnly(errors.New("test"))
+			So(fail.OriginatedIn(err, "github.com/nbgo/fail_test"), ShouldBeTrue)
+			So(fail.OriginatedIn(errors.New("test"), "github.com/nbgo/fail_test"), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,122 @@
+package fail
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/stack.v1"
+)
+
+// Recover recovers panic and assigns it as an error to the given error pointer.
+// It is intended to be deferred: defer fail.Recover(&err).
+// If the panic value is an error it is used as the original error, otherwise an error is created from the value.
+// Location and stack trace of the resulting error point to the place where the panic occurred.
+// If there is no panic then the error pointed to is left untouched.
+func Recover(errPtr *error) {
+	if recovered := recover(); recovered != nil {
+		*errPtr = newPanicError(recovered)
+	}
+}
+
+// Go runs the given function in a new goroutine and sends its result to the returned channel, which is closed then.
+// If the function panics then the panic is recovered and sent as an error like Recover does,
+// so a panicking goroutine does not crash the process.
+func Go(fn func() error) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		var err error
+		defer func() {
+			result <- err
+			close(result)
+		}()
+		defer Recover(&err)
+		err = fn()
+	}()
+	return result
+}
+
+// Must returns the given value if the given error is nil and panics otherwise.
+// Panic value is error created by New from the given error, so it points to the Must caller and has stack trace.
+// It is intended for initialization code where an error is unrecoverable: cfg := fail.Must(loadConfig()).
+func Must[T any](value T, err error) T {
+	if err != nil {
+		panic(New(err, 1))
+	}
+	return value
+}
+
+// Must0 panics like Must does if the given error is not nil.
+func Must0(err error) {
+	if err != nil {
+		panic(New(err, 1))
+	}
+}
+
+// FromPanic converts the given recovered panic value to an error like Recover does, but outside of the deferred function:
+// an error value is used as the original error, a string value becomes error message and any other value is formatted with %v.
+// Unlike Recover, location and stack trace are captured at the FromPanic call site.
+// Can be called with optional integer single parameter which defines how many closest callers skip.
+// If the given value is nil then nil is returned.
+func FromPanic(recovered interface{}, additionalStackSkip ...int) error {
+	if recovered == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	return New(panicValueToError(recovered), stackSkip)
+}
+
+// panicValueToError returns the given panic value if it is an error or an error created from it otherwise.
+func panicValueToError(recovered interface{}) error {
+	switch value := recovered.(type) {
+	case error:
+		return value
+	case string:
+		return errors.New(value)
+	}
+	return fmt.Errorf("%v", recovered)
+}
+
+// newPanicError creates error from the recovered panic value, it has to be called by the deferred function
+// which recovered the panic (directly), so the stack trace of the panic can be captured.
+func newPanicError(recovered interface{}) error {
+	err := panicValueToError(recovered)
+	if stackCapturer != nil {
+		// frames replaced by SetStackCapturer are used instead of the stack trace of the panic
+		return New(err, 2)
+	}
+
+	// skip newPanicError, deferred function and runtime panic handling
+	stackTrace := stack.Trace()[2:]
+	for len(stackTrace) > 1 && isRuntimeCall(stackTrace[0]) {
+		stackTrace = stackTrace[1:]
+	}
+	stackTrace = stackTrace.TrimRuntime()
+	if len(stackTrace) == 0 {
+		return New(err, 2)
+	}
+	return enrich(&extendedError{
+		originalError: err,
+		location:      stackTrace[0],
+		stackTrace:    panicStackTrace(stackTrace),
+		meta:          captureMetadata(),
+	})
+}
+
+// panicStackTrace returns the given stack trace of the panic limited by MaxStackDepth or nil if CaptureStackTraces is disabled.
+func panicStackTrace(stackTrace stack.CallStack) stack.CallStack {
+	if !CaptureStackTraces {
+		return nil
+	}
+	return limitStackTrace(stackTrace)
+}
+
+// isRuntimeCall checks if the call is a call of function from runtime package.
+func isRuntimeCall(call stack.Call) bool {
+	return strings.HasPrefix(fmt.Sprintf("%+n", call), "runtime.")
+}
@@ -0,0 +1,146 @@
+package fail_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPanic(t *testing.T) {
+	Convey("Recover()", t, func() {
+		Convey("should convert panic with error value", func() {
+			panicErr := errors.New("panic error")
+			err := recoverPanicOf(panicErr)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "panic error")
+			So(fail.Is(err, panicErr), ShouldBeTrue)
+			So(fail.GetLocation(err), ShouldContainSubstring, "panic_test.go")
+			So(fail.GetLocation(err), ShouldContainSubstring, "(panicWith)")
+			So(fail.GetStackFrames(err)[1].Function, ShouldEqual, "recoverPanicOf")
+		})
+
+		Convey("should convert panic with string value", func() {
+			err := recoverPanicOf("panic text")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "panic text")
+			So(fail.GetLocation(err), ShouldContainSubstring, "(panicWith)")
+		})
+
+		Convey("should capture location of runtime panic", func() {
+			var err error
+			func() {
+				defer fail.Recover(&err)
+				var myErr *MyError
+				_ = myErr.msg
+			}()
+			So(err, ShouldNotBeNil)
+			So(fail.GetLocation(err), ShouldContainSubstring, "panic_test.go")
+			So(fail.GetLocation(err), ShouldContainSubstring, "TestPanic.")
+		})
+
+		Convey("should leave error untouched when there is no panic", func() {
+			existingErr := errors.New("existing")
+			err := existingErr
+			func() {
+				defer fail.Recover(&err)
+			}()
+			So(err, ShouldEqual, existingErr)
+		})
+	})
+
+	Convey("FromPanic()", t, func() {
+		recovered := func(value interface{}) (result interface{}) {
+			defer func() { result = recover() }()
+			panicWith(value)
+			return nil
+		}
+
+		Convey("should keep error value as original error", func() {
+			panicErr := errors.New("panic error")
+			err := fail.FromPanic(recovered(panicErr))
+			So(err.Error(), ShouldEqual, "panic error")
+			So(fail.Is(err, panicErr), ShouldBeTrue)
+			So(fail.GetLocation(err), ShouldContainSubstring, "panic_test.go")
+			So(fail.GetStackTrace(err), ShouldNotBeEmpty)
+		})
+
+		Convey("should create error from string value", func() {
+			err := fail.FromPanic(recovered("panic text"))
+			So(err.Error(), ShouldEqual, "panic text")
+			So(fail.GetType(err), ShouldEqual, fail.GetType(errors.New("")))
+		})
+
+		Convey("should format other values", func() {
+			err := fail.FromPanic(recovered(struct{ Code int }{42}))
+			So(err.Error(), ShouldEqual, "{42}")
+		})
+
+		Convey("should return nil for nil value", func() {
+			So(fail.FromPanic(nil), ShouldBeNil)
+		})
+	})
+
+	Convey("Must() and Must0()", t, func() {
+		Convey("should return value if there is no error", func() {
+			So(fail.Must(42, nil), ShouldEqual, 42)
+			So(func() { fail.Must0(nil) }, ShouldNotPanic)
+		})
+
+		Convey("should panic with error having stack trace", func() {
+			cause := errors.New("cannot load config")
+			for _, mustFn := range []func(){
+				func() { fail.Must("", cause) },
+				func() { fail.Must0(cause) },
+			} {
+				var recovered interface{}
+				func() {
+					defer func() { recovered = recover() }()
+					mustFn()
+				}()
+				err, isErr := recovered.(error)
+				So(isErr, ShouldBeTrue)
+				So(err.Error(), ShouldEqual, "cannot load config")
+				So(fail.Is(err, cause), ShouldBeTrue)
+				So(fail.GetStackTrace(err), ShouldNotBeEmpty)
+				So(fail.GetLocation(err), ShouldContainSubstring, "panic_test.go")
+			}
+		})
+	})
+
+	Convey("Go()", t, func() {
+		Convey("should send error returned by function", func() {
+			returnedErr := errors.New("returned")
+			result := fail.Go(func() error { return returnedErr })
+			So(<-result, ShouldEqual, returnedErr)
+			_, isOpen := <-result
+			So(isOpen, ShouldBeFalse)
+		})
+
+		Convey("should send nil if function returns nil", func() {
+			So(<-fail.Go(func() error { return nil }), ShouldBeNil)
+		})
+
+		Convey("should send recovered panic with stack trace", func() {
+			err := <-fail.Go(func() error {
+				panicWith("panic text")
+				return nil
+			})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "panic text")
+			So(fail.GetLocation(err), ShouldContainSubstring, "(panicWith)")
+			So(fail.GetStackTrace(err), ShouldContainSubstring, "panic_test.go")
+		})
+	})
+}
+
+func recoverPanicOf(value interface{}) (err error) {
+	defer fail.Recover(&err)
+	panicWith(value)
+	return nil
+}
+
+func panicWith(value interface{}) {
+	panic(value)
+}
@@ -0,0 +1,76 @@
+package fail
+
+import (
+	"runtime"
+	"sync"
+
+	"gopkg.in/stack.v1"
+)
+
+// Pool reuses errors created by this package to reduce allocations in very hot paths
+// where transient errors are created and handled immediately (e.g. in a tight loop).
+// It is opt-in: errors created by other constructors are not pooled unless they are put to the pool.
+// Errors must not be used or retained anywhere (including their wrappers and values returned
+// by their methods like Fields) after they are put to the pool, as they are reset and reused by subsequent Get calls.
+// Pooling does not reduce allocations once enrichers are registered by RegisterEnricher,
+// as enrichers adding metadata (like WithField does) return a new error instead of the pooled one.
+// Zero value is ready to use. It is safe for concurrent use.
+type Pool struct {
+	pool sync.Pool
+}
+
+// Get creates a new error like New does reusing an error put to the pool earlier if there is any.
+// Stack trace is captured like NewLazy does into the buffer of the reused error,
+// so only the location of the error is allocated unless the stack trace is read.
+func (pool *Pool) Get(err error, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	extErr, _ := pool.pool.Get().(*extendedError)
+	if extErr == nil {
+		extErr = &extendedError{}
+	}
+	if !CaptureStackTraces || stackCapturer != nil {
+		extErr.lazyStack = nil
+		initExtendedError(extErr, err, nil, stackSkip)
+		return enrich(extErr)
+	}
+
+	lazyStack := extErr.lazyStack
+	if lazyStack == nil {
+		lazyStack = &lazyStackTrace{}
+	}
+	pcs := lazyStack.pcs[:cap(lazyStack.pcs)]
+	if len(pcs) < maxLazyStackDepth {
+		pcs = make([]uintptr, maxLazyStackDepth)
+	}
+	// runtime.Callers counts itself, so one more frame has to be skipped comparing to stack.Caller
+	lazyStack.pcs = pcs[:runtime.Callers(stackSkip+1, pcs)]
+
+	extErr.originalError = err
+	extErr.location = stack.Caller(stackSkip)
+	extErr.lazyStack = lazyStack
+	extErr.meta = captureMetadata()
+	return enrich(extErr)
+}
+
+// Put resets the given error and puts it to the pool for reuse by Get.
+// Buffer of its stack trace is kept for reuse unless the stack trace has been read.
+// Errors not created by this package are ignored.
+func (pool *Pool) Put(err error) {
+	if extErr, isExtErr := err.(*extendedError); isExtErr {
+		lazyStack := extErr.lazyStack
+		*extErr = extendedError{}
+		if lazyStack != nil {
+			*lazyStack = lazyStackTrace{pcs: lazyStack.pcs[:0]}
+			extErr.lazyStack = lazyStack
+		}
+		pool.pool.Put(extErr)
+	}
+}
@@ -0,0 +1,80 @@
+package fail_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPool(t *testing.T) {
+	Convey("Pool", t, func() {
+		var pool fail.Pool
+
+		Convey("should create errors like New does", func() {
+			originalErr := errors.New("test")
+			err := pool.Get(originalErr)
+			So(err.Error(), ShouldEqual, "test")
+			So(fail.GetOriginalError(err), ShouldEqual, originalErr)
+			So(fail.GetLocation(err), ShouldContainSubstring, "pool_test.go")
+			So(fail.GetStackFrames(err)[0].Function, ShouldEqual, fail.GetStackFrames(fail.New(originalErr))[0].Function)
+			So(fail.GetStackFrames(err)[1:], ShouldResemble, fail.GetStackFrames(fail.New(originalErr))[1:])
+		})
+
+		Convey("should reset errors on reuse", func() {
+			used := fail.WithField(fail.NewWithCode(fail.NewWithInner(errors.New("used"), errors.New("inner")), "CODE"), "key", "value")
+			pool.Put(used)
+			for i := 0; i < 10; i++ {
+				err := pool.Get(errors.New("reused"))
+				So(err.Error(), ShouldEqual, "reused")
+				So(fail.GetInner(err), ShouldBeNil)
+				So(fail.GetCode(err), ShouldBeEmpty)
+				So(fail.GetFields(err), ShouldBeNil)
+				So(fail.GetLocation(err), ShouldContainSubstring, "pool_test.go")
+				pool.Put(err)
+			}
+		})
+
+		Convey("should capture new stack trace on reuse", func() {
+			for i := 0; i < 10; i++ {
+				err := createPooledErrorInHelper(&pool)
+				So(fail.GetStackFrames(err)[0].Function, ShouldEqual, "createPooledErrorInHelper")
+				if i%2 == 0 {
+					So(len(fail.GetStackFrames(err)), ShouldBeGreaterThan, 1)
+				}
+				pool.Put(err)
+				err = pool.Get(errors.New("reused"))
+				So(fail.GetStackFrames(err)[0].Function, ShouldStartWith, "TestPool.")
+				pool.Put(err)
+			}
+		})
+
+		Convey("should ignore errors not created by this package", func() {
+			pool.Put(errors.New("test"))
+			pool.Put(nil)
+			So(pool.Get(nil), ShouldBeNil)
+		})
+	})
+}
+
+func BenchmarkPool(b *testing.B) {
+	err := errors.New("test")
+	b.Run("New", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = fail.New(err)
+		}
+	})
+	b.Run("Get and Put", func(b *testing.B) {
+		var pool fail.Pool
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			pool.Put(pool.Get(err))
+		}
+	})
+}
+
+func createPooledErrorInHelper(pool *fail.Pool) error {
+	return pool.Get(errors.New("test"))
+}
@@ -0,0 +1,52 @@
+package fail
+
+import "sync"
+
+// Recorder keeps a bounded number of the most recently recorded errors (e.g. to show them on a debug endpoint).
+// It is safe for concurrent use.
+type Recorder struct {
+	mutex sync.Mutex
+	errs  []error
+	next  int
+	full  bool
+}
+
+// NewRecorder creates recorder keeping up to size most recent errors.
+// It panics if size is not positive.
+func NewRecorder(size int) *Recorder {
+	if size <= 0 {
+		panic("fail: recorder size must be positive")
+	}
+	return &Recorder{errs: make([]error, size)}
+}
+
+// Record stores the given error evicting the oldest one if the recorder is full.
+// Nil errors are ignored.
+func (recorder *Recorder) Record(err error) {
+	if err == nil {
+		return
+	}
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.errs[recorder.next] = err
+	recorder.next++
+	if recorder.next == len(recorder.errs) {
+		recorder.next = 0
+		recorder.full = true
+	}
+}
+
+// Recent returns recorded errors from the oldest to the newest one.
+func (recorder *Recorder) Recent() []error {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	if !recorder.full {
+		return append([]error(nil), recorder.errs[:recorder.next]...)
+	}
+	result := make([]error, 0, len(recorder.errs))
+	result = append(result, recorder.errs[recorder.next:]...)
+	return append(result, recorder.errs[:recorder.next]...)
+}
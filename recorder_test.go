@@ -0,0 +1,52 @@
+package fail_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRecorder(t *testing.T) {
+	Convey("Recorder", t, func() {
+		recorder := fail.NewRecorder(3)
+		err1, err2, err3, err4 := fail.News("1"), fail.News("2"), fail.News("3"), fail.News("4")
+
+		Convey("should return recorded errors from the oldest one", func() {
+			So(recorder.Recent(), ShouldBeEmpty)
+			recorder.Record(err1)
+			recorder.Record(nil)
+			recorder.Record(err2)
+			So(recorder.Recent(), ShouldResemble, []error{err1, err2})
+		})
+
+		Convey("should evict the oldest errors on overflow", func() {
+			for _, err := range []error{err1, err2, err3, err4} {
+				recorder.Record(err)
+			}
+			So(recorder.Recent(), ShouldResemble, []error{err2, err3, err4})
+			recorder.Record(err1)
+			recorder.Record(err2)
+			So(recorder.Recent(), ShouldResemble, []error{err4, err1, err2})
+		})
+
+		Convey("should support concurrent recording", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 100; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					recorder.Record(err1)
+					recorder.Recent()
+				}()
+			}
+			wg.Wait()
+			So(recorder.Recent(), ShouldResemble, []error{err1, err1, err1})
+		})
+
+		Convey("should panic for non-positive size", func() {
+			So(func() { fail.NewRecorder(0) }, ShouldPanic)
+		})
+	})
+}
@@ -0,0 +1,44 @@
+package fail
+
+import "sync"
+
+// RedactedValue is rendered instead of values of fields with keys registered by RegisterRedactedFieldKey.
+const RedactedValue = "***"
+
+var (
+	redactedFieldKeysMutex sync.RWMutex
+	redactedFieldKeys      = map[string]bool{}
+)
+
+// RegisterRedactedFieldKey registers field key, so values of fields with such key are rendered as RedactedValue
+// by GetFullDetails, MarshalJSON and logging helpers (e.g. for fields containing passwords or tokens).
+// Raw values are still returned by GetFields and GetAllFields.
+// It is safe for concurrent use.
+func RegisterRedactedFieldKey(key string) {
+	redactedFieldKeysMutex.Lock()
+	defer redactedFieldKeysMutex.Unlock()
+
+	redactedFieldKeys[key] = true
+}
+
+// RedactFields returns copy of the given fields with values of fields with keys registered
+// by RegisterRedactedFieldKey replaced by RedactedValue.
+// It is intended for code rendering fields for humans or logs.
+// If there are no fields nil is returned.
+func RedactFields(fields map[string]interface{}) map[string]interface{} {
+	redactedFieldKeysMutex.RLock()
+	defer redactedFieldKeysMutex.RUnlock()
+
+	var result map[string]interface{}
+	for key, value := range fields {
+		if result == nil {
+			result = make(map[string]interface{}, len(fields))
+		}
+		if redactedFieldKeys[key] {
+			result[key] = RedactedValue
+		} else {
+			result[key] = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package fail_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRedactedFields(t *testing.T) {
+	Convey("RegisterRedactedFieldKey()", t, func() {
+		fail.RegisterRedactedFieldKey("password")
+		inner := fail.WithField(errors.New("invalid credentials"), "password", "qwerty")
+		err := fail.NewWithInner(fail.WithField(errors.New("login failed"), "user", "bob"), inner)
+
+		Convey("GetFullDetails() should render redacted value", func() {
+			details := fail.GetFullDetails(err)
+			So(details, ShouldContainSubstring, "fields: user=bob")
+			So(details, ShouldContainSubstring, "fields: password=***")
+			So(details, ShouldNotContainSubstring, "qwerty")
+		})
+
+		Convey("MarshalJSON() should render redacted value", func() {
+			data, jsonErr := fail.MarshalJSON(inner)
+			So(jsonErr, ShouldBeNil)
+			var result struct{ Fields map[string]interface{} }
+			So(json.Unmarshal(data, &result), ShouldBeNil)
+			So(result.Fields, ShouldResemble, map[string]interface{}{"password": fail.RedactedValue})
+		})
+
+		Convey("GetFields() should return raw value", func() {
+			So(fail.GetFields(err), ShouldResemble, map[string]interface{}{"user": "bob", "password": "qwerty"})
+		})
+	})
+
+	Convey("RedactFields()", t, func() {
+		fail.RegisterRedactedFieldKey("token")
+
+		Convey("should return copy with redacted values", func() {
+			fields := map[string]interface{}{"token": "secret", "id": 1}
+			So(fail.RedactFields(fields), ShouldResemble, map[string]interface{}{"token": fail.RedactedValue, "id": 1})
+			So(fields["token"], ShouldEqual, "secret")
+		})
+
+		Convey("should return nil for no fields", func() {
+			So(fail.RedactFields(nil), ShouldBeNil)
+		})
+	})
+}
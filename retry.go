@@ -0,0 +1,95 @@
+package fail
+
+import "time"
+
+// Retryable is error which provides a hint whether the failed operation can be retried.
+//
+// RetryAfter returns how long to wait before retrying and true if the operation is retryable,
+// or false if it is not.
+type Retryable interface {
+	error
+	RetryAfter() (time.Duration, bool)
+}
+
+type retryability int8
+
+const (
+	retryabilityUnspecified retryability = iota
+	retryabilityRetryable
+	retryabilityNonRetryable
+)
+
+func (extErr extendedError) RetryAfter() (time.Duration, bool) {
+	meta := extErr.metadata()
+	switch meta.retryability {
+	case retryabilityRetryable:
+		return meta.retryAfter, true
+	case retryabilityNonRetryable:
+		return 0, false
+	}
+	if retryable, isRetryable := extErr.originalError.(Retryable); isRetryable {
+		return retryable.RetryAfter()
+	}
+	return 0, false
+}
+
+// NewRetryable creates a new error like New does and marks it as retryable after the given duration.
+// Newly created error implements Retryable.
+func NewRetryable(err error, retryAfter time.Duration, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	extErr := newExtendedError(err, nil, stackSkip)
+	meta := extErr.writableMetadata()
+	meta.retryability = retryabilityRetryable
+	meta.retryAfter = retryAfter
+	return enrich(extErr)
+}
+
+// NewNonRetryable creates a new error like New does and marks it as non-retryable
+// overriding retry hints of the errors it wraps.
+// Newly created error implements Retryable.
+func NewNonRetryable(err error, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	extErr := newExtendedError(err, nil, stackSkip)
+	extErr.writableMetadata().retryability = retryabilityNonRetryable
+	return enrich(extErr)
+}
+
+// GetRetryAfter returns retry hint of the first error with retry hint found in the chain of the given error
+// (the chain is walked the same way as in Is), so outer errors override inner ones.
+// It returns how long to wait before retrying and true if the error is retryable, or false otherwise
+// (including the case when there is no retry hint at all).
+func GetRetryAfter(err error) (time.Duration, bool) {
+	var retryAfter time.Duration
+	var isRetryable bool
+	walk(err, func(currErr error) bool {
+		if extErr, isExtErr := currErr.(*extendedError); isExtErr {
+			if extErr.metadata().retryability == retryabilityUnspecified {
+				return true
+			}
+			retryAfter, isRetryable = extErr.RetryAfter()
+			return false
+		}
+		if retryable, hasRetryHint := currErr.(Retryable); hasRetryHint {
+			retryAfter, isRetryable = retryable.RetryAfter()
+			return false
+		}
+		return true
+	})
+	return retryAfter, isRetryable
+}
@@ -0,0 +1,68 @@
+package fail_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type MyRetryableError struct{}
+
+func (err MyRetryableError) Error() string {
+	return "temporarily unavailable"
+}
+
+func (err MyRetryableError) RetryAfter() (time.Duration, bool) {
+	return time.Minute, true
+}
+
+func TestRetry(t *testing.T) {
+	Convey("NewRetryable() and GetRetryAfter()", t, func() {
+		Convey("should return retry hint after wrapping", func() {
+			err := fail.Wrap(fail.NewRetryable(errors.New("busy"), time.Second), "calling service")
+			retryAfter, ok := fail.GetRetryAfter(err)
+			So(ok, ShouldBeTrue)
+			So(retryAfter, ShouldEqual, time.Second)
+		})
+
+		Convey("should implement Retryable", func() {
+			retryAfter, ok := fail.NewRetryable(errors.New("busy"), time.Second).(fail.Retryable).RetryAfter()
+			So(ok, ShouldBeTrue)
+			So(retryAfter, ShouldEqual, time.Second)
+			retryAfter, ok = fail.New(MyRetryableError{}).(fail.Retryable).RetryAfter()
+			So(ok, ShouldBeTrue)
+			So(retryAfter, ShouldEqual, time.Minute)
+		})
+
+		Convey("should support third-party retryable errors", func() {
+			retryAfter, ok := fail.GetRetryAfter(fail.Wrap(MyRetryableError{}, "calling service"))
+			So(ok, ShouldBeTrue)
+			So(retryAfter, ShouldEqual, time.Minute)
+		})
+
+		Convey("should not be retryable without hint", func() {
+			_, ok := fail.GetRetryAfter(fail.Wrap(fail.News("test"), "calling service"))
+			So(ok, ShouldBeFalse)
+		})
+	})
+
+	Convey("NewNonRetryable()", t, func() {
+		Convey("should override inner retryable error", func() {
+			err := fail.NewNonRetryable(fail.Wrap(fail.NewRetryable(errors.New("busy"), time.Second), "calling service"))
+			_, ok := fail.GetRetryAfter(err)
+			So(ok, ShouldBeFalse)
+			_, ok = fail.GetRetryAfter(fail.NewNonRetryable(MyRetryableError{}))
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("should be overridden by outer retryable error", func() {
+			err := fail.NewRetryable(fail.NewNonRetryable(errors.New("busy")), time.Hour)
+			retryAfter, ok := fail.GetRetryAfter(err)
+			So(ok, ShouldBeTrue)
+			So(retryAfter, ShouldEqual, time.Hour)
+		})
+	})
+}
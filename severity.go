@@ -0,0 +1,81 @@
+package fail
+
+import "fmt"
+
+// Severity is the level of error severity.
+// Severities are ordered: SeverityDebug < SeverityWarning < SeverityError < SeverityCritical.
+// Zero value means that severity is not specified.
+type Severity int
+
+// Supported severities in ascending order.
+const (
+	SeverityDebug Severity = iota + 1
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+func (severity Severity) String() string {
+	switch severity {
+	case SeverityDebug:
+		return "debug"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("Severity(%d)", int(severity))
+}
+
+// ErrorWithSeverity is error which provides its severity.
+//
+// Severity returns error's severity or zero value if severity is not specified.
+type ErrorWithSeverity interface {
+	error
+	Severity() Severity
+}
+
+func (extErr extendedError) Severity() Severity {
+	if severity := extErr.metadata().severity; severity != 0 {
+		return severity
+	}
+	if errWithSeverity, isErrWithSeverity := extErr.originalError.(ErrorWithSeverity); isErrWithSeverity {
+		return errWithSeverity.Severity()
+	}
+	return 0
+}
+
+// NewWithSeverity creates a new error like New does and attaches the given severity to it.
+// Newly created error implements ErrorWithSeverity.
+func NewWithSeverity(err error, severity Severity, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	extErr := newExtendedError(err, nil, stackSkip)
+	extErr.writableMetadata().severity = severity
+	return enrich(extErr)
+}
+
+// GetSeverity returns the highest severity found in the chain of the given error
+// (the chain is walked the same way as in Is).
+// If there is no error with specified severity then false is returned.
+func GetSeverity(err error) (Severity, bool) {
+	var result Severity
+	walk(err, func(currErr error) bool {
+		if errWithSeverity, isErrWithSeverity := currErr.(ErrorWithSeverity); isErrWithSeverity {
+			if severity := errWithSeverity.Severity(); severity > result {
+				result = severity
+			}
+		}
+		return true
+	})
+	return result, result != 0
+}
@@ -0,0 +1,49 @@
+package fail_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSeverity(t *testing.T) {
+	Convey("Severity", t, func() {
+		Convey("should be ordered", func() {
+			So(fail.SeverityDebug, ShouldBeLessThan, fail.SeverityWarning)
+			So(fail.SeverityWarning, ShouldBeLessThan, fail.SeverityError)
+			So(fail.SeverityError, ShouldBeLessThan, fail.SeverityCritical)
+		})
+
+		Convey("should have string representation", func() {
+			So(fail.SeverityCritical.String(), ShouldEqual, "critical")
+			So(fail.Severity(0).String(), ShouldEqual, "Severity(0)")
+		})
+	})
+
+	Convey("NewWithSeverity() and GetSeverity()", t, func() {
+		Convey("should return severity of error", func() {
+			err := fail.NewWithSeverity(errors.New("test"), fail.SeverityWarning)
+			So(err.(fail.ErrorWithSeverity).Severity(), ShouldEqual, fail.SeverityWarning)
+			severity, ok := fail.GetSeverity(fail.New(err))
+			So(ok, ShouldBeTrue)
+			So(severity, ShouldEqual, fail.SeverityWarning)
+		})
+
+		Convey("should report critical inner error wrapped by warning outer", func() {
+			inner := fail.NewWithSeverity(errors.New("inner"), fail.SeverityCritical)
+			err := fail.NewWithInner(fail.NewWithSeverity(errors.New("outer"), fail.SeverityWarning), inner)
+			severity, ok := fail.GetSeverity(err)
+			So(ok, ShouldBeTrue)
+			So(severity, ShouldEqual, fail.SeverityCritical)
+		})
+
+		Convey("should report absence of severity", func() {
+			_, ok := fail.GetSeverity(fail.NewErrWithReason("test", fail.News("inner")))
+			So(ok, ShouldBeFalse)
+			_, ok = fail.GetSeverity(errors.New("test"))
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
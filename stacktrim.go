@@ -0,0 +1,131 @@
+package fail
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// DefaultStackTrimPrefixes are package path prefixes registered by default (and by ResetStackTrimPrefixes),
+// so stack traces end at user code. Use RemoveStackTrimPrefix to see frames of these packages again.
+var DefaultStackTrimPrefixes = []string{"testing", "runtime", "gopkg.in/stack.v1"}
+
+var (
+	stackTrimPrefixesMutex sync.RWMutex
+	stackTrimPrefixes      = append([]string(nil), DefaultStackTrimPrefixes...)
+)
+
+// AddStackTrimPrefix registers package path prefix, so frames of functions from packages with such prefix
+// are dropped from all stack traces produced by this package (e.g. frames of web framework or test harness).
+// Prefix matches whole path elements, e.g. "testing" matches "testing" and "testing/quick", but not "testingutil".
+// It is safe for concurrent use.
+func AddStackTrimPrefix(pkgPath string) {
+	stackTrimPrefixesMutex.Lock()
+	defer stackTrimPrefixesMutex.Unlock()
+
+	for _, prefix := range stackTrimPrefixes {
+		if prefix == pkgPath {
+			return
+		}
+	}
+	stackTrimPrefixes = append(stackTrimPrefixes, pkgPath)
+}
+
+// RemoveStackTrimPrefix unregisters package path prefix registered by AddStackTrimPrefix.
+// It is safe for concurrent use.
+func RemoveStackTrimPrefix(pkgPath string) {
+	stackTrimPrefixesMutex.Lock()
+	defer stackTrimPrefixesMutex.Unlock()
+
+	var result []string
+	for _, prefix := range stackTrimPrefixes {
+		if prefix != pkgPath {
+			result = append(result, prefix)
+		}
+	}
+	stackTrimPrefixes = result
+}
+
+// ResetStackTrimPrefixes unregisters all package path prefixes registered by AddStackTrimPrefix
+// and registers DefaultStackTrimPrefixes again.
+// It is safe for concurrent use.
+func ResetStackTrimPrefixes() {
+	stackTrimPrefixesMutex.Lock()
+	defer stackTrimPrefixesMutex.Unlock()
+
+	stackTrimPrefixes = append([]string(nil), DefaultStackTrimPrefixes...)
+}
+
+// trimFrames returns frames without frames of packages registered by AddStackTrimPrefix.
+func trimFrames(frames []Frame) []Frame {
+	stackTrimPrefixesMutex.RLock()
+	defer stackTrimPrefixesMutex.RUnlock()
+
+	if len(stackTrimPrefixes) == 0 {
+		return frames
+	}
+
+	var result []Frame
+	for _, frame := range frames {
+		if !hasStackTrimPrefix(frame.Package) {
+			result = append(result, frame)
+		}
+	}
+	return result
+}
+
+func hasStackTrimPrefix(pkgPath string) bool {
+	for _, prefix := range stackTrimPrefixes {
+		if pkgPath == prefix || strings.HasPrefix(pkgPath, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// NewTrimmedAbove creates a new error like New does, but its stack trace ends right before the frame
+// of the boundary function, so the boundary function and all its callers are dropped
+// (e.g. plumbing of a worker pool running the code which creates the error).
+// Boundary is either a function value or a fully qualified function name as reported by runtime.FuncForPC
+// (like "github.com/user/pool.(*Pool).run"). At least the top frame is always kept.
+// If the boundary function is not found in the stack trace then the whole stack trace is kept.
+// NewTrimmedAbove panics if boundary is neither a function nor a string.
+func NewTrimmedAbove(err error, boundary interface{}, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	boundaryName := functionName(boundary)
+	extErr := newExtendedError(err, nil, stackSkip)
+	stackTrace := extErr.stackTrace
+	for i, call := range stackTrace {
+		if fmt.Sprintf("%+n", call) == boundaryName {
+			if i == 0 {
+				i = 1
+			}
+			stackTrace = stackTrace[:i]
+			break
+		}
+	}
+	extErr.stackTrace = stackTrace
+	return enrich(extErr)
+}
+
+// functionName returns fully qualified name of the given function value or the given name itself.
+func functionName(function interface{}) string {
+	if name, isName := function.(string); isName {
+		return name
+	}
+	functionValue := reflect.ValueOf(function)
+	if functionValue.Kind() != reflect.Func || functionValue.IsNil() {
+		panic("fail: boundary must be function or function name")
+	}
+	return runtime.FuncForPC(functionValue.Pointer()).Name()
+}
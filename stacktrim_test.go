@@ -0,0 +1,131 @@
+package fail_test
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/stack.v1"
+)
+
+func TestStackTrim(t *testing.T) {
+	Convey("AddStackTrimPrefix()", t, func() {
+		defer fail.ResetStackTrimPrefixes()
+		err := fail.News("test")
+		lazyErr := fail.NewLazy(err)
+		So(hasFrameOfPackage(fail.GetStackFrames(err), "github.com/smartystreets/goconvey"), ShouldBeTrue)
+
+		fail.AddStackTrimPrefix("github.com/smartystreets/goconvey")
+		fail.AddStackTrimPrefix("github.com/jtolds/gls")
+
+		Convey("should drop frames of registered packages", func() {
+			for _, frames := range [][]fail.Frame{fail.GetStackFrames(err), fail.GetStackFrames(lazyErr), fail.StackTraceToFrames(stack.Trace())} {
+				So(hasFrameOfPackage(frames, "github.com/smartystreets/goconvey"), ShouldBeFalse)
+				So(hasFrameOfPackage(frames, "github.com/jtolds/gls"), ShouldBeFalse)
+			}
+		})
+
+		Convey("should keep other frames", func() {
+			frames := fail.GetStackFrames(err)
+			So(len(frames), ShouldBeGreaterThan, 1)
+			for _, frame := range frames {
+				So(frame.Package, ShouldEqual, "github.com/nbgo/fail_test")
+				So(fileName(frame), ShouldEqual, "stacktrim_test.go")
+			}
+		})
+
+		Convey("should restore frames when prefix is removed", func() {
+			fail.RemoveStackTrimPrefix("github.com/smartystreets/goconvey")
+			frames := fail.GetStackFrames(err)
+			So(hasFrameOfPackage(frames, "github.com/smartystreets/goconvey"), ShouldBeTrue)
+			So(hasFrameOfPackage(frames, "github.com/jtolds/gls"), ShouldBeFalse)
+			So(fileName(frames[0]), ShouldEqual, "stacktrim_test.go")
+		})
+	})
+
+	Convey("Default stack trim prefixes", t, func() {
+		defer fail.ResetStackTrimPrefixes()
+		untrimmedStackTrace := stack.Trace()
+		packagesOf := func(frames []fail.Frame) []string {
+			var result []string
+			for _, frame := range frames {
+				result = append(result, frame.Package)
+			}
+			return result
+		}
+
+		Convey("should drop frames of testing, runtime and stack packages", func() {
+			packages := packagesOf(fail.StackTraceToFrames(untrimmedStackTrace))
+			So(packages, ShouldNotContain, "testing")
+			So(packages, ShouldNotContain, "runtime")
+			So(packages, ShouldNotContain, "gopkg.in/stack.v1")
+			So(packages, ShouldContain, "github.com/nbgo/fail_test")
+		})
+
+		Convey("should be overridable", func() {
+			fail.RemoveStackTrimPrefix("testing")
+			So(packagesOf(fail.StackTraceToFrames(untrimmedStackTrace)), ShouldContain, "testing")
+
+			fail.ResetStackTrimPrefixes()
+			So(packagesOf(fail.StackTraceToFrames(untrimmedStackTrace)), ShouldNotContain, "testing")
+		})
+	})
+
+	Convey("NewTrimmedAbove()", t, func() {
+		Convey("should drop frames of boundary function and its callers", func() {
+			var err error
+			runInPool(func() { err = fail.NewTrimmedAbove(fail.News("test"), runInPool) })
+			frames := fail.GetStackFrames(err)
+			So(frames, ShouldHaveLength, 1)
+			So(frames[0].Function, ShouldStartWith, "TestStackTrim.")
+			So(fail.GetStackTrace(err), ShouldNotContainSubstring, "runInPool")
+			So(fail.GetStackTrace(err), ShouldNotContainSubstring, "goconvey")
+		})
+
+		Convey("should accept function name", func() {
+			var err error
+			runInPool(func() { err = fail.NewTrimmedAbove(fail.News("test"), "github.com/nbgo/fail_test.runInPool") })
+			So(fail.GetStackFrames(err), ShouldHaveLength, 1)
+		})
+
+		Convey("should keep top frame if error is created in boundary function", func() {
+			frames := fail.GetStackFrames(newTrimmedInBoundary())
+			So(frames, ShouldHaveLength, 1)
+			So(frames[0].Function, ShouldEqual, "newTrimmedInBoundary")
+		})
+
+		Convey("should keep whole stack trace if boundary is not found", func() {
+			err := fail.NewTrimmedAbove(fail.News("test"), strings.ToUpper)
+			So(fail.GetStackTrace(err), ShouldContainSubstring, "goconvey")
+		})
+
+		Convey("should panic if boundary is not function", func() {
+			So(func() { fail.NewTrimmedAbove(fail.News("test"), 42) }, ShouldPanic)
+		})
+	})
+}
+
+func runInPool(task func()) {
+	task()
+}
+
+func newTrimmedInBoundary() error {
+	return fail.NewTrimmedAbove(fail.News("test"), newTrimmedInBoundary)
+}
+
+func hasFrameOfPackage(frames []fail.Frame, pkgPathPrefix string) bool {
+	for _, frame := range frames {
+		if strings.HasPrefix(frame.Package, pkgPathPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// fileName returns name of the file of the given frame without its path,
+// which is rendered differently in GOPATH and module builds.
+func fileName(frame fail.Frame) string {
+	return path.Base(frame.File)
+}
@@ -0,0 +1,75 @@
+package fail
+
+// Tagged is error which provides string tags for quick categorization (like "transient" or "user-error").
+//
+// Tags returns error's tags or nil if there are no tags.
+type Tagged interface {
+	error
+	Tags() []string
+}
+
+func (extErr extendedError) Tags() []string {
+	var originalTags []string
+	if tagged, isTagged := extErr.originalError.(Tagged); isTagged && !extErr.sealed {
+		originalTags = tagged.Tags()
+	}
+	return mergeTags(originalTags, extErr.metadata().tags)
+}
+
+// WithTag returns an error with the given tags added to the tags of the given error.
+// If the given error is created by this package then the result keeps its location and stack trace,
+// otherwise the given error is wrapped capturing location and stack trace of the WithTag caller.
+// Newly created error implements Tagged.
+func WithTag(err error, tags ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	if extErr, isExtErr := err.(*extendedError); isExtErr {
+		extErrCopy := *extErr
+		extErrCopy.writableMetadata().tags = mergeTags(extErr.metadata().tags, tags)
+		return &extErrCopy
+	}
+	extErr := newExtendedError(err, nil, 1)
+	extErr.writableMetadata().tags = mergeTags(nil, tags)
+	return enrich(extErr)
+}
+
+// GetTags returns unique tags of all errors in the chain of the given error
+// (the chain is walked the same way as in GetFields) in the order they are found. If there are no tags nil is returned.
+func GetTags(err error) []string {
+	var result []string
+	walkMetadata(err, func(currErr error) bool {
+		if tagged, isTagged := currErr.(Tagged); isTagged {
+			result = mergeTags(result, tagged.Tags())
+		}
+		return true
+	})
+	return result
+}
+
+// HasTag checks if any error in the chain of the given error has the given tag (see GetTags).
+func HasTag(err error, tag string) bool {
+	for _, errTag := range GetTags(err) {
+		if errTag == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeTags returns a new slice containing unique tags from all given slices in order of their appearance.
+// If there are no tags nil is returned.
+func mergeTags(tagSlices ...[]string) []string {
+	var result []string
+	seen := map[string]bool{}
+	for _, tags := range tagSlices {
+		for _, tag := range tags {
+			if !seen[tag] {
+				seen[tag] = true
+				result = append(result, tag)
+			}
+		}
+	}
+	return result
+}
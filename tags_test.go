@@ -0,0 +1,47 @@
+package fail_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTags(t *testing.T) {
+	Convey("WithTag()", t, func() {
+		Convey("should attach unique tags", func() {
+			err := fail.WithTag(errors.New("timeout"), "transient", "network", "transient")
+			So(err.(fail.Tagged).Tags(), ShouldResemble, []string{"transient", "network"})
+			So(fail.GetLocation(err), ShouldContainSubstring, "tags_test.go")
+		})
+
+		Convey("should keep location of error created by this package", func() {
+			extErr := fail.News("test")
+			err := fail.WithTag(fail.WithTag(extErr, "a"), "b")
+			So(fail.GetLocation(err), ShouldEqual, fail.GetLocation(extErr))
+			So(fail.GetTags(err), ShouldResemble, []string{"a", "b"})
+			So(fail.GetTags(extErr), ShouldBeNil)
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(fail.WithTag(nil, "a"), ShouldBeNil)
+		})
+	})
+
+	Convey("GetTags() and HasTag()", t, func() {
+		inner := fail.WithTag(errors.New("timeout"), "transient", "network")
+		err := fail.WithTag(fail.Wrap(inner, "loading user"), "user", "transient")
+
+		Convey("should aggregate unique tags across the chain", func() {
+			So(fail.GetTags(err), ShouldResemble, []string{"user", "transient", "network"})
+		})
+
+		Convey("should look up tags across the chain", func() {
+			So(fail.HasTag(err, "network"), ShouldBeTrue)
+			So(fail.HasTag(err, "user"), ShouldBeTrue)
+			So(fail.HasTag(err, "user-error"), ShouldBeFalse)
+			So(fail.HasTag(nil, "user"), ShouldBeFalse)
+		})
+	})
+}
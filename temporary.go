@@ -0,0 +1,50 @@
+package fail
+
+// temporaryError is implemented by errors which report whether they are temporary (like net.Error does).
+type temporaryError interface {
+	error
+	Temporary() bool
+}
+
+// Temporary reports whether the error is temporary: either it is created by NewTemporary
+// or its original error is temporary. It makes errors created by this package compatible with checks like
+// the one of net.Error.
+func (extErr extendedError) Temporary() bool {
+	if extErr.metadata().temporary {
+		return true
+	}
+	if tempErr, isTempErr := extErr.originalError.(temporaryError); isTempErr {
+		return tempErr.Temporary()
+	}
+	return false
+}
+
+// NewTemporary creates a new error like New does and marks it as temporary.
+// Newly created error has Temporary method returning true.
+func NewTemporary(err error, additionalStackSkip ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	stackSkip := 1
+	if len(additionalStackSkip) > 0 {
+		stackSkip += additionalStackSkip[0]
+	}
+
+	extErr := newExtendedError(err, nil, stackSkip)
+	extErr.writableMetadata().temporary = true
+	return enrich(extErr)
+}
+
+// IsTemporary checks if any error in the chain of the given error (the chain is walked the same way as in Is)
+// has Temporary method (like net.Error does) which returns true.
+func IsTemporary(err error) bool {
+	found := false
+	walk(err, func(currErr error) bool {
+		if tempErr, isTempErr := currErr.(temporaryError); isTempErr {
+			found = tempErr.Temporary()
+		}
+		return !found
+	})
+	return found
+}
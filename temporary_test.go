@@ -0,0 +1,41 @@
+package fail_test
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTemporary(t *testing.T) {
+	Convey("IsTemporary()", t, func() {
+		Convey("should detect wrapped temporary net error", func() {
+			netErr := &net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true}
+			err := fail.Wrap(fail.New(netErr), "resolving host")
+			So(fail.IsTemporary(err), ShouldBeTrue)
+			So(fail.New(netErr).(interface{ Temporary() bool }).Temporary(), ShouldBeTrue)
+		})
+
+		Convey("should not detect non-temporary errors", func() {
+			netErr := &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}
+			So(fail.IsTemporary(fail.Wrap(netErr, "resolving host")), ShouldBeFalse)
+			So(fail.IsTemporary(fail.News("test")), ShouldBeFalse)
+			So(fail.IsTemporary(nil), ShouldBeFalse)
+		})
+	})
+
+	Convey("NewTemporary()", t, func() {
+		Convey("should mark error as temporary", func() {
+			err := fail.NewTemporary(errors.New("connection reset"))
+			So(err.(interface{ Temporary() bool }).Temporary(), ShouldBeTrue)
+			So(fail.IsTemporary(fail.Wrap(err, "loading user")), ShouldBeTrue)
+			So(fail.GetLocation(err), ShouldContainSubstring, "temporary_test.go")
+		})
+
+		Convey("should return nil for nil error", func() {
+			So(fail.NewTemporary(nil), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,36 @@
+package fail
+
+import "time"
+
+// CaptureTimestamps enables capturing of the time when an error is created.
+// Captured time is available via GetTime and is written by GetFullDetails as "time T:" header line.
+// It is disabled by default as it adds overhead to error creation.
+var CaptureTimestamps = false
+
+// GetTime returns the time when the first error with captured time found in the chain of the given error was created
+// (the chain is walked the same way as in Is).
+// If there is no such error then false is returned.
+func GetTime(err error) (time.Time, bool) {
+	var result time.Time
+	walk(err, func(currErr error) bool {
+		result = ownTime(currErr)
+		return result.IsZero()
+	})
+	return result, !result.IsZero()
+}
+
+// ownTime returns time captured by the given error itself or zero time if there is none.
+func ownTime(err error) time.Time {
+	if extErr, isExtErr := err.(*extendedError); isExtErr {
+		return extErr.metadata().time
+	}
+	return time.Time{}
+}
+
+// captureTime returns the current time or zero time if CaptureTimestamps is disabled.
+func captureTime() time.Time {
+	if !CaptureTimestamps {
+		return time.Time{}
+	}
+	return time.Now()
+}
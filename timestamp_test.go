@@ -0,0 +1,48 @@
+package fail_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTimestamps(t *testing.T) {
+	Convey("GetTime()", t, func() {
+		Convey("should return false if capturing is disabled", func() {
+			_, ok := fail.GetTime(fail.News("test"))
+			So(ok, ShouldBeFalse)
+			_, ok = fail.GetTime(errors.New("plain"))
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("with capturing enabled", func() {
+			fail.CaptureTimestamps = true
+			defer func() { fail.CaptureTimestamps = false }()
+
+			Convey("should return time close to now", func() {
+				errTime, ok := fail.GetTime(fail.NewWithInner(errors.New("test"), nil))
+				So(ok, ShouldBeTrue)
+				So(errTime, ShouldHappenWithin, time.Second, time.Now())
+			})
+
+			Convey("should return time of inner error", func() {
+				inner := fail.News("inner")
+				fail.CaptureTimestamps = false
+				errTime, ok := fail.GetTime(fail.Wrap(inner, "outer"))
+				So(ok, ShouldBeTrue)
+				So(errTime, ShouldHappenWithin, time.Second, time.Now())
+			})
+
+			Convey("GetFullDetails() should write time header line", func() {
+				err := fail.News("test")
+				errTime, _ := fail.GetTime(err)
+				So(strings.HasPrefix(fail.GetFullDetails(err), fmt.Sprintf("time %v:\n*errors.errorString: test", errTime.Format(time.RFC3339Nano))), ShouldBeTrue)
+			})
+		})
+	})
+}
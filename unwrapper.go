@@ -0,0 +1,42 @@
+package fail
+
+import "sync"
+
+var (
+	unwrappersMutex sync.RWMutex
+	unwrappers      []func(error) error
+)
+
+// RegisterUnwrapper registers function returning the cause of third-party errors which expose it in their own way
+// (e.g. by Cause method of github.com/pkg/errors). The function should return nil for errors it does not know.
+// Registered unwrappers are consulted (in registration order until non-nil result) by GetInner, Cause, Is, As
+// and other functions walking the chain for errors which do not implement CompositeError and do not have Unwrap method.
+// It is safe for concurrent use.
+func RegisterUnwrapper(unwrapper func(error) error) {
+	unwrappersMutex.Lock()
+	defer unwrappersMutex.Unlock()
+
+	unwrappers = append(unwrappers, unwrapper)
+}
+
+// ResetUnwrappers unregisters all unwrappers registered by RegisterUnwrapper.
+// It is safe for concurrent use.
+func ResetUnwrappers() {
+	unwrappersMutex.Lock()
+	defer unwrappersMutex.Unlock()
+
+	unwrappers = nil
+}
+
+// unwrapByRegistered returns the cause of the given error found by registered unwrappers or nil.
+func unwrapByRegistered(err error) error {
+	unwrappersMutex.RLock()
+	defer unwrappersMutex.RUnlock()
+
+	for _, unwrapper := range unwrappers {
+		if cause := unwrapper(err); cause != nil {
+			return cause
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package fail_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbgo/fail"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// causeError exposes its cause like errors of github.com/pkg/errors do.
+type causeError struct {
+	msg   string
+	cause error
+}
+
+func (err *causeError) Error() string {
+	return err.msg + ": " + err.cause.Error()
+}
+
+func (err *causeError) Cause() error {
+	return err.cause
+}
+
+func TestUnwrappers(t *testing.T) {
+	Convey("RegisterUnwrapper()", t, func() {
+		root := errors.New("not found")
+		err := fail.New(&causeError{"loading user", &causeError{"querying", root}})
+		So(fail.Is(err, root), ShouldBeFalse)
+
+		defer fail.ResetUnwrappers()
+		fail.RegisterUnwrapper(func(err error) error {
+			if causer, isCauser := err.(interface{ Cause() error }); isCauser {
+				return causer.Cause()
+			}
+			return nil
+		})
+
+		Convey("should make Is and As traverse Cause chain", func() {
+			So(fail.Is(err, root), ShouldBeTrue)
+			var causeErr *causeError
+			So(fail.As(err, &causeErr), ShouldBeTrue)
+			So(causeErr.msg, ShouldEqual, "loading user")
+		})
+
+		Convey("should make GetInner and Cause traverse Cause chain", func() {
+			causeErr := fail.GetOriginalError(err)
+			So(fail.GetInner(causeErr).(*causeError).msg, ShouldEqual, "querying")
+			So(fail.Cause(err), ShouldEqual, root)
+			So(fail.Depth(causeErr), ShouldEqual, 3)
+		})
+
+		Convey("should not be used after reset", func() {
+			fail.ResetUnwrappers()
+			So(fail.Is(err, root), ShouldBeFalse)
+			So(fail.Cause(err), ShouldEqual, fail.GetOriginalError(err))
+		})
+	})
+}